@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/amsokol/protobuf-rest/runtime"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// httpBodyName is full name of google.api.HttpBody message, it is bound to raw HTTP body.
+const httpBodyName = "google.api.HttpBody"
+
+// binding is HTTP binding of proto method.
+type binding struct {
+	method       string // HTTP method
+	template     string // path template
+	body         string // field path of request body, "*" or empty
+	responseBody string // field path of response body or empty
+	updateMask   string // field path of update mask populated from request body or empty
+}
+
+// methodBindings returns HTTP bindings of the method from google.api.http option
+// and rule of gRPC API Configuration with the method selector. The rule is merged with the option
+// or, if override_inline_http_rules is set, replaces it. Methods without rules are bound
+// to POST /<package>.<Service>/<Method> if generate_unbound_methods is set.
+func methodBindings(m *protogen.Method, o *options) ([]*binding, error) {
+	rule := methodHTTPRule(m, o)
+	if rule == nil && o.generateUnboundMethods {
+		rule = &annotations.HttpRule{
+			Pattern: &annotations.HttpRule_Post{Post: "/" + string(m.Parent.Desc.FullName()) + "/" + string(m.Desc.Name())},
+			Body:    "*",
+		}
+	}
+
+	if rule == nil {
+		return nil, nil
+	}
+
+	rules := append([]*annotations.HttpRule{rule}, rule.GetAdditionalBindings()...)
+	bb := make([]*binding, 0, len(rules))
+	routes := make(map[string]*binding, len(rules))
+
+	for _, r := range rules {
+		b, err := newBinding(m, r, o)
+		if err != nil {
+			return nil, fmt.Errorf("method '%s': %w", m.Desc.FullName(), err)
+		}
+
+		key, err := b.route()
+		if err != nil {
+			return nil, fmt.Errorf("method '%s': %w", m.Desc.FullName(), err)
+		}
+
+		if d, ok := routes[key]; ok {
+			if *d == *b {
+				// the same binding is declared inline and in gRPC API Configuration
+				continue
+			}
+
+			return nil, fmt.Errorf("method '%s': duplicate HTTP binding %s", m.Desc.FullName(), key)
+		}
+
+		routes[key] = b
+		bb = append(bb, b)
+	}
+
+	return bb, nil
+}
+
+// methodHTTPRule returns HTTP rule of the method declared with google.api.http option
+// or in gRPC API Configuration, nil if the method is not bound explicitly.
+func methodHTTPRule(m *protogen.Method, o *options) *annotations.HttpRule {
+	rule, ok := proto.GetExtension(m.Desc.Options(), annotations.E_Http).(*annotations.HttpRule)
+	if !ok {
+		rule = nil
+	}
+
+	if r, ok := o.httpRules[string(m.Desc.FullName())]; ok {
+		if rule == nil || o.overrideInlineHTTPRules {
+			rule = r
+		} else {
+			rule = mergeHTTPRules(rule, r)
+		}
+	}
+
+	return rule
+}
+
+// mergeHTTPRules returns inline rule with bindings of rule r added as additional bindings.
+func mergeHTTPRules(inline *annotations.HttpRule, r *annotations.HttpRule) *annotations.HttpRule {
+	merged := proto.Clone(inline).(*annotations.HttpRule)
+
+	add := proto.Clone(r).(*annotations.HttpRule)
+	additional := add.AdditionalBindings
+	add.Selector, add.AdditionalBindings = "", nil
+
+	merged.AdditionalBindings = append(append(merged.AdditionalBindings, add), additional...)
+
+	return merged
+}
+
+func newBinding(m *protogen.Method, r *annotations.HttpRule, o *options) (*binding, error) {
+	b := &binding{
+		body:         r.GetBody(),
+		responseBody: r.GetResponseBody(),
+	}
+
+	switch p := r.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		b.method, b.template = http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		b.method, b.template = http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		b.method, b.template = http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		b.method, b.template = http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		b.method, b.template = http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		b.method, b.template = p.Custom.GetKind(), p.Custom.GetPath()
+	default:
+		return nil, fmt.Errorf("HTTP pattern is not set")
+	}
+
+	if len(b.method) == 0 {
+		return nil, fmt.Errorf("HTTP method of custom pattern is not set")
+	}
+
+	if b.method == http.MethodDelete && len(b.body) > 0 && !o.allowDeleteBody {
+		return nil, fmt.Errorf("DELETE binding '%s' must not have body, set allow_delete_body=true to allow it", b.template)
+	}
+
+	if m.Desc.IsStreamingClient() && b.body != "*" {
+		return nil, fmt.Errorf("binding '%s' of client streaming method must have body \"*\"", b.template)
+	}
+
+	if err := b.validate(m.Input.Desc, m.Output.Desc); err != nil {
+		return nil, err
+	}
+
+	if o.allowPatchFeature {
+		b.updateMask = b.updateMaskField(m.Input.Desc)
+	}
+
+	return b, nil
+}
+
+// updateMaskField returns name of update_mask field of request message if PATCH request body
+// populates it, see https://google.aip.dev/134. The binding must be PATCH with message field body
+// that is not google.api.HttpBody and update_mask must be google.protobuf.FieldMask.
+func (b *binding) updateMaskField(in protoreflect.MessageDescriptor) string {
+	if b.method != http.MethodPatch || len(b.body) == 0 || b.body == "*" {
+		return ""
+	}
+
+	fds, err := runtime.FieldByPath(in, b.body)
+	if err != nil {
+		return ""
+	}
+
+	if fd := fds[len(fds)-1]; fd.Message() == nil || fd.IsList() || fd.IsMap() || fd.Message().FullName() == httpBodyName {
+		return ""
+	}
+
+	fd := in.Fields().ByName("update_mask")
+	if fd == nil || fd.IsList() || fd.Message() == nil || fd.Message().FullName() != "google.protobuf.FieldMask" {
+		return ""
+	}
+
+	return string(fd.Name())
+}
+
+// route returns HTTP method and canonical path template of the binding, e.g. "GET /v1/{name=*}".
+func (b *binding) route() (string, error) {
+	p, err := runtime.NewPath(b.template)
+	if err != nil {
+		return "", err
+	}
+
+	return b.method + " " + p.String(), nil
+}
+
+// validate checks that path template is valid and fields of path variables,
+// body and response body exist in request and response messages.
+func (b *binding) validate(in protoreflect.MessageDescriptor, out protoreflect.MessageDescriptor) error {
+	p, err := runtime.NewPath(b.template)
+	if err != nil {
+		return err
+	}
+
+	for s := p; s != nil; s = s.Next {
+		if len(s.Field) > 0 && !s.IsVal {
+			if _, err := runtime.FieldByPath(in, s.Field); err != nil {
+				return fmt.Errorf("path template '%s': %w", b.template, err)
+			}
+		}
+	}
+
+	if len(b.body) > 0 && b.body != "*" {
+		if _, err := runtime.FieldByPath(in, b.body); err != nil {
+			return fmt.Errorf("body: %w", err)
+		}
+	}
+
+	if len(b.responseBody) > 0 {
+		if _, err := runtime.FieldByPath(out, b.responseBody); err != nil {
+			return fmt.Errorf("response_body: %w", err)
+		}
+	}
+
+	return nil
+}
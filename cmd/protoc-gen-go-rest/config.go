@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/encoding/protojson"
+	"sigs.k8s.io/yaml"
+)
+
+// loadHTTPRules reads HTTP rules of gRPC API Configuration file, i.e. YAML of google.api.Service
+// with http.rules, and returns them by selector, i.e. full method name.
+// Wildcard selectors, e.g. "pkg.Service.*", are rejected, as a binding of path template cannot be shared by methods.
+// See https://cloud.google.com/endpoints/docs/grpc/grpc-service-config.
+func loadHTTPRules(path string) (map[string]*annotations.HttpRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read gRPC API configuration: %w", err)
+	}
+
+	rules, err := parseHTTPRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("gRPC API configuration '%s': %w", path, err)
+	}
+
+	return rules, nil
+}
+
+func parseHTTPRules(data []byte) (map[string]*annotations.HttpRule, error) {
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// only http section of google.api.Service is used
+	var service struct {
+		HTTP json.RawMessage `json:"http"`
+	}
+
+	if err := json.Unmarshal(data, &service); err != nil {
+		return nil, err
+	}
+
+	http := &annotations.Http{}
+
+	if len(service.HTTP) > 0 {
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(service.HTTP, http); err != nil {
+			return nil, fmt.Errorf("parse http rules: %w", err)
+		}
+	}
+
+	rules := make(map[string]*annotations.HttpRule, len(http.GetRules()))
+
+	for _, r := range http.GetRules() {
+		if len(r.GetSelector()) == 0 {
+			return nil, fmt.Errorf("http rule selector is not set")
+		}
+
+		if strings.Contains(r.GetSelector(), "*") {
+			return nil, fmt.Errorf("http rule selector '%s': wildcards are not supported, use full method name", r.GetSelector())
+		}
+
+		if _, ok := rules[r.GetSelector()]; ok {
+			return nil, fmt.Errorf("duplicate http rule of selector '%s'", r.GetSelector())
+		}
+
+		rules[r.GetSelector()] = r
+	}
+
+	return rules, nil
+}
+
+// checkSelectors returns error if selector of any rule is not full name of method of the request files.
+func checkSelectors(gen *protogen.Plugin, rules map[string]*annotations.HttpRule) error {
+	methods := make(map[string]struct{})
+
+	for _, f := range gen.Files {
+		for _, s := range f.Services {
+			for _, m := range s.Methods {
+				methods[string(m.Desc.FullName())] = struct{}{}
+			}
+		}
+	}
+
+	var unknown []string
+
+	for selector := range rules {
+		if _, ok := methods[selector]; !ok {
+			unknown = append(unknown, selector)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+
+	return fmt.Errorf("gRPC API configuration: http rule selectors %q do not match any method", unknown)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"testing"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseHTTPRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]*annotations.HttpRule
+		wantErr bool
+	}{
+		{
+			"rules",
+			`
+type: google.api.Service
+config_version: 3
+name: example.googleapis.com
+http:
+  fully_decode_reserved_expansion: true
+  rules:
+  - selector: example.v1.Service.Get
+    get: /v1/{name=items/*}
+  - selector: example.v1.Service.Create
+    post: /v1/items
+    body: item
+    additionalBindings:
+    - custom: {kind: HEAD, path: /v1/items}
+`,
+			map[string]*annotations.HttpRule{
+				"example.v1.Service.Get": {
+					Selector: "example.v1.Service.Get",
+					Pattern:  &annotations.HttpRule_Get{Get: "/v1/{name=items/*}"},
+				},
+				"example.v1.Service.Create": {
+					Selector: "example.v1.Service.Create",
+					Pattern:  &annotations.HttpRule_Post{Post: "/v1/items"},
+					Body:     "item",
+					AdditionalBindings: []*annotations.HttpRule{
+						{Pattern: &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Kind: "HEAD", Path: "/v1/items"}}},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"no http section",
+			`type: google.api.Service`,
+			map[string]*annotations.HttpRule{},
+			false,
+		},
+		{
+			"no selector",
+			`
+http:
+  rules:
+  - get: /v1/items
+`,
+			nil,
+			true,
+		},
+		{
+			"duplicate selector",
+			`
+http:
+  rules:
+  - selector: example.v1.Service.Get
+    get: /v1/items
+  - selector: example.v1.Service.Get
+    get: /v2/items
+`,
+			nil,
+			true,
+		},
+		{
+			"wildcard selector",
+			`
+http:
+  rules:
+  - selector: example.v1.Service.*
+    get: /v1/items
+`,
+			nil,
+			true,
+		},
+		{
+			"invalid rule",
+			`
+http:
+  rules:
+  - selector: example.v1.Service.Get
+    get: [/v1/items]
+`,
+			nil,
+			true,
+		},
+		{
+			"invalid YAML",
+			`http: [`,
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHTTPRules([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHTTPRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseHTTPRules() got = %v, want %v", got, tt.want)
+			}
+
+			for k, w := range tt.want {
+				if !proto.Equal(got[k], w) {
+					t.Errorf("parseHTTPRules() got[%s] = %v, want %v", k, got[k], w)
+				}
+			}
+		})
+	}
+}
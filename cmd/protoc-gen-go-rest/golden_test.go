@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	_ "google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata/golden")
+
+// request compiles proto file testdata/<file> and returns CodeGeneratorRequest to generate it.
+// Imports are resolved in testdata, then in descriptors of Go packages linked into the test,
+// e.g. google/api/annotations.proto.
+func request(t *testing.T, file string, params string) *pluginpb.CodeGeneratorRequest {
+	t.Helper()
+
+	c := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(protocompile.CompositeResolver{
+			&protocompile.SourceResolver{ImportPaths: []string{"testdata"}},
+			protocompile.ResolverFunc(func(path string) (protocompile.SearchResult, error) {
+				fd, err := protoregistry.GlobalFiles.FindFileByPath(path)
+				if err != nil {
+					return protocompile.SearchResult{}, err
+				}
+
+				return protocompile.SearchResult{Desc: fd}, nil
+			}),
+		}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+
+	files, err := c.Compile(context.Background(), file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &pluginpb.CodeGeneratorRequest{
+		FileToGenerate: []string{file},
+		Parameter:      proto.String(params),
+	}
+
+	seen := make(map[string]bool)
+
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+
+		seen[fd.Path()] = true
+
+		// dependencies precede files that import them
+		for i := 0; i < fd.Imports().Len(); i++ {
+			add(fd.Imports().Get(i).FileDescriptor)
+		}
+
+		req.ProtoFile = append(req.ProtoFile, protodesc.ToFileDescriptorProto(fd))
+	}
+
+	for _, f := range files {
+		add(f)
+	}
+
+	// plugin reads serialized request, so options are parsed as generated extension types
+	data, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req = &pluginpb.CodeGeneratorRequest{}
+	if err := proto.Unmarshal(data, req); err != nil {
+		t.Fatal(err)
+	}
+
+	return req
+}
+
+// compareGolden compares generated files with golden files in dir, or overwrites them with -update.
+func compareGolden(t *testing.T, dir string, got map[string]string) {
+	t.Helper()
+
+	if *update {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+
+		writeFiles(t, dir, got)
+
+		return
+	}
+
+	want := make(map[string]string)
+
+	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		want[filepath.ToSlash(name)] = string(data)
+
+		return nil
+	}); err != nil {
+		t.Fatalf("read golden files: %v, run tests with -update to create them", err)
+	}
+
+	for name, content := range got {
+		if w, ok := want[name]; !ok {
+			t.Errorf("unexpected file %s", name)
+		} else if w != content {
+			t.Errorf("file %s differs from golden file, run tests with -update to update it\ngot:\n%s", name, content)
+		}
+	}
+
+	for name := range want {
+		if _, ok := got[name]; !ok {
+			t.Errorf("missing file %s", name)
+		}
+	}
+}
+
+// compileGenerated builds generated REST files with message and gRPC code of the request
+// in temporary module that uses runtime packages of the repository.
+func compileGenerated(t *testing.T, req *pluginpb.CodeGeneratorRequest, rest map[string]string) {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("compile check is skipped in short mode")
+	}
+
+	req = proto.Clone(req).(*pluginpb.CodeGeneratorRequest)
+	req.Parameter = proto.String("paths=source_relative")
+
+	files := make(map[string]string)
+
+	// messages and gRPC services
+	for _, plugin := range []string{"google.golang.org/protobuf/cmd/protoc-gen-go", "google.golang.org/grpc/cmd/protoc-gen-go-grpc"} {
+		addResponseFiles(t, files, runPlugin(t, plugin, req))
+	}
+
+	for name, content := range rest {
+		if strings.HasSuffix(name, ".go") {
+			files[name] = content
+		}
+	}
+
+	dir := t.TempDir()
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the module requires the same versions of dependencies as the repository
+	mod, err := os.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files["go.mod"] = strings.Replace(string(mod), "module github.com/amsokol/protobuf-rest", "module example.com", 1) +
+		"\nrequire github.com/amsokol/protobuf-rest v0.0.0\n\nreplace github.com/amsokol/protobuf-rest => " + root + "\n"
+	files["go.sum"] = string(sum)
+
+	writeFiles(t, dir, files)
+
+	cmd := exec.Command("go", "build", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=readonly", "GOWORK=off")
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("compile generated code: %v\n%s", err, out)
+	}
+}
+
+// runPlugin runs protoc plugin package, that is required by the repository module (see tools.go), with the request.
+func runPlugin(t *testing.T, plugin string, req *pluginpb.CodeGeneratorRequest) *pluginpb.CodeGeneratorResponse {
+	t.Helper()
+
+	data, err := proto.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("go", "run", plugin)
+	cmd.Stdin = bytes.NewReader(data)
+
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("run %s: %v", plugin, err)
+	}
+
+	resp := &pluginpb.CodeGeneratorResponse{}
+	if err := proto.Unmarshal(out, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	return resp
+}
+
+func addResponseFiles(t *testing.T, files map[string]string, resp *pluginpb.CodeGeneratorResponse) {
+	t.Helper()
+
+	if resp.Error != nil {
+		t.Fatalf("generate: %s", resp.GetError())
+	}
+
+	for _, f := range resp.File {
+		files[f.GetName()] = f.GetContent()
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -3,9 +3,12 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 
+	"google.golang.org/genproto/googleapis/api/annotations"
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/descriptorpb"
 	"google.golang.org/protobuf/types/pluginpb"
 )
 
@@ -13,45 +16,116 @@ const (
 	_version = "v0.1.0"
 )
 
+// stderr receives generator warnings, protoc prints them.
+var stderr io.Writer = os.Stderr
+
+// options are generator options passed as protoc plugin parameters,
+// e.g. --go-rest_opt=register_func_suffix=Handler,standalone=true.
+type options struct {
+	registerFuncSuffix      string
+	standalone              bool
+	omitPackageDoc          bool
+	allowDeleteBody         bool
+	allowPatchFeature       bool
+	warnOnUnboundMethods    bool
+	generateUnboundMethods  bool
+	jsonNamesForFields      bool
+	grpcAPIConfiguration    string
+	overrideInlineHTTPRules bool
+
+	httpRules map[string]*annotations.HttpRule // rules of grpcAPIConfiguration by selector
+}
+
+// register defines flags of the options in fs.
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.registerFuncSuffix, "register_func_suffix", "REST",
+		"suffix of generated Register<Service><Suffix>Server functions")
+	fs.BoolVar(&o.standalone, "standalone", false,
+		"generate into separate <package>rest Go package that imports the package of proto messages")
+	fs.BoolVar(&o.omitPackageDoc, "omit_package_doc", false,
+		"omit package doc comment of generated files")
+	fs.BoolVar(&o.allowDeleteBody, "allow_delete_body", false,
+		"allow DELETE bindings with request body")
+	fs.BoolVar(&o.allowPatchFeature, "allow_patch_feature", true,
+		"populate google.protobuf.FieldMask update_mask of PATCH requests from request body")
+	fs.BoolVar(&o.warnOnUnboundMethods, "warn_on_unbound_methods", false,
+		"print warning for methods without HTTP bindings")
+	fs.BoolVar(&o.generateUnboundMethods, "generate_unbound_methods", false,
+		"bind methods without HTTP bindings to POST /<package>.<Service>/<Method> with body \"*\"")
+	fs.BoolVar(&o.jsonNamesForFields, "json_names_for_fields", true,
+		"marshal JSON with lowerCamelCase JSON names, proto field names are used if false")
+	fs.StringVar(&o.grpcAPIConfiguration, "grpc_api_configuration", "",
+		"path of gRPC API Configuration YAML file with HTTP rules of methods")
+	fs.BoolVar(&o.overrideInlineHTTPRules, "override_inline_http_rules", false,
+		"replace google.api.http options with gRPC API Configuration rules of the same methods instead of merging them")
+}
+
+// validate checks that the options are consistent.
+func (o *options) validate() error {
+	if len(o.registerFuncSuffix) == 0 && !o.standalone {
+		return fmt.Errorf("empty register_func_suffix requires standalone=true, " +
+			"Register<Service>Server functions conflict with gRPC generated code")
+	}
+
+	return nil
+}
+
 func main() {
-	// useInputFile := flag.String("input_file", "", "use file instead of stdin")
+	var o options
+
+	o.register(flag.CommandLine)
 	showVersion := flag.Bool("version", false, "print the current version")
 
 	flag.Parse()
 
 	if *showVersion {
-		fmt.Printf("protoc-gen-protobuf-rest %v\n", _version)
+		fmt.Printf("protoc-gen-go-rest %v\n", _version)
 
 		return
 	}
 
-	/*
-		// safe input data for DEBUG
-		data, err := io.ReadAll(os.Stdin)
+	// to debug the plugin save CodeGeneratorRequest of protoc to file, e.g. ./stdin.debug,
+	// and run the plugin with the file as standard input
+	protogen.Options{
+		ParamFunc: flag.CommandLine.Set,
+	}.Run(func(gen *protogen.Plugin) error {
+		return generate(gen, &o)
+	})
+}
+
+// generate generates _rest.pb.go files of the files to generate.
+func generate(gen *protogen.Plugin, o *options) error {
+	if err := o.validate(); err != nil {
+		return err
+	}
+
+	if len(o.grpcAPIConfiguration) > 0 {
+		rules, err := loadHTTPRules(o.grpcAPIConfiguration)
 		if err != nil {
-			return
+			return err
 		}
-		_ = os.WriteFile("./stdin.debug", data, 0666)
-		return
-	*/
 
-	r, err := os.Open("../../stdin.debug")
-	if err != nil {
-		return
+		if err := checkSelectors(gen, rules); err != nil {
+			return err
+		}
+
+		o.httpRules = rules
 	}
 
-	protogen.Options{
-		ParamFunc: flag.CommandLine.Set,
-		DebugFile: r,
-	}.Run(func(gen *protogen.Plugin) error {
-		gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL)
-		for _, f := range gen.Files {
-			if !f.Generate {
-				continue
-			}
-			generateFile(gen, f)
+	gen.SupportedFeatures = uint64(pluginpb.CodeGeneratorResponse_FEATURE_PROTO3_OPTIONAL |
+		pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS)
+	gen.SupportedEditionsMinimum = descriptorpb.Edition_EDITION_PROTO2
+	gen.SupportedEditionsMaximum = descriptorpb.Edition_EDITION_2023
+
+	for _, f := range gen.Files {
+		if !f.Generate {
+			continue
 		}
 
-		return nil
-	})
+		if _, err := generateFile(gen, f, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
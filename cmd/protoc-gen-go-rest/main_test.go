@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestGenerate(t *testing.T) {
+	const (
+		library    = "library/v1/library.proto"
+		deleteBody = "deletebody/v1/delete_body.proto"
+		proto2     = "proto2/v1/shelf.proto"
+		editions   = "editions/v1/shelf.proto"
+		upload     = "upload/v1/upload.proto"
+		httpBody   = "httpbody/v1/image.proto"
+		streamBody = "streambody/v1/upload.proto"
+		auth       = "auth/v1/books.proto"
+		authGRPC   = "auth/v1/admin.proto"
+		rateLimit  = "ratelimit/v1/search.proto"
+	)
+
+	tests := []struct {
+		name    string
+		file    string
+		params  string
+		wantErr string
+	}{
+		{"default", library, "paths=source_relative", ""},
+		{"register_func_suffix", library, "paths=source_relative,register_func_suffix=Handler", ""},
+		{"standalone", library, "paths=source_relative,standalone=true,register_func_suffix=", ""},
+		{"omit_package_doc", library, "paths=source_relative,omit_package_doc=true", ""},
+		{"allow_patch_feature", library, "paths=source_relative,allow_patch_feature=false", ""},
+		{"warn_on_unbound_methods", library, "paths=source_relative,warn_on_unbound_methods=true", ""},
+		{"json_names_for_fields", library, "paths=source_relative,json_names_for_fields=false", ""},
+		{"generate_unbound_methods", library, "paths=source_relative,generate_unbound_methods=true", ""},
+		{"grpc_api_configuration", library, "paths=source_relative,grpc_api_configuration=testdata/library.yaml", ""},
+		{"override_inline_http_rules", library, "paths=source_relative,grpc_api_configuration=testdata/library.yaml,override_inline_http_rules=true", ""},
+		{"limits", upload, "paths=source_relative", ""},
+		{"httpbody", httpBody, "paths=source_relative", ""},
+		{"auth", auth, "paths=source_relative", ""},
+		{"auth_without_bindings", authGRPC, "paths=source_relative", ""},
+		{"rate_limit", rateLimit, "paths=source_relative", ""},
+		{"allow_delete_body", deleteBody, "paths=source_relative,allow_delete_body=true", ""},
+		{"proto2", proto2, "paths=source_relative", ""},
+		{"editions", editions, "paths=source_relative", ""},
+		{"client stream body", streamBody, "paths=source_relative", `client streaming method must have body "*"`},
+		{"delete_body", deleteBody, "paths=source_relative", "must not have body"},
+		{"empty register_func_suffix", library, "paths=source_relative,register_func_suffix=", "requires standalone"},
+		{"missing grpc_api_configuration", library, "paths=source_relative,grpc_api_configuration=testdata/missing.yaml", "read gRPC API configuration"},
+		{"unknown selector", library, "paths=source_relative,grpc_api_configuration=testdata/unknown_selector.yaml", "LibraryService.GetShelf"},
+		{"duplicate binding", library, "paths=source_relative,grpc_api_configuration=testdata/duplicate_binding.yaml", "duplicate HTTP binding GET /v1/{name=shelves/*/books/*}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var warnings bytes.Buffer
+
+			stderr = &warnings
+			defer func() { stderr = os.Stderr }()
+
+			var o options
+
+			fs := flag.NewFlagSet(tt.name, flag.ContinueOnError)
+			o.register(fs)
+
+			req := request(t, tt.file, tt.params)
+
+			gen, err := protogen.Options{ParamFunc: fs.Set}.New(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = generate(gen, &o)
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("generate() error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("generate() error = %v", err)
+			}
+
+			resp := gen.Response()
+			if resp.Error != nil {
+				t.Fatalf("generate() response error = %v", resp.GetError())
+			}
+
+			if resp.GetSupportedFeatures()&uint64(pluginpb.CodeGeneratorResponse_FEATURE_SUPPORTS_EDITIONS) == 0 ||
+				resp.GetMinimumEdition() != int32(descriptorpb.Edition_EDITION_PROTO2) ||
+				resp.GetMaximumEdition() != int32(descriptorpb.Edition_EDITION_2023) {
+				t.Errorf("generate() supported features = %d, editions %d - %d",
+					resp.GetSupportedFeatures(), resp.GetMinimumEdition(), resp.GetMaximumEdition())
+			}
+
+			got := make(map[string]string, len(resp.File)+1)
+			for _, f := range resp.File {
+				got[f.GetName()] = f.GetContent()
+			}
+
+			if warnings.Len() > 0 {
+				got["stderr.txt"] = warnings.String()
+			}
+
+			compareGolden(t, filepath.Join("testdata", "golden", tt.name), got)
+			compileGenerated(t, req, got)
+		})
+	}
+}
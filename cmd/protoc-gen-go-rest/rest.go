@@ -2,21 +2,55 @@ package main
 
 import (
 	"fmt"
+	"path"
+	"strconv"
+	"strings"
 
+	"github.com/amsokol/protobuf-rest/rest"
 	"google.golang.org/protobuf/compiler/protogen"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	contextPackage = protogen.GoImportPath("context")
+	grpcPackage    = protogen.GoImportPath("google.golang.org/grpc")
+	httpPackage    = protogen.GoImportPath("net/http")
+	restPackage    = protogen.GoImportPath("github.com/amsokol/protobuf-rest/runtime/http")
 )
 
 // generateFile generates a _rest.pb.go file containing REST service definitions.
-func generateFile(gen *protogen.Plugin, file *protogen.File) *protogen.GeneratedFile {
+func generateFile(gen *protogen.Plugin, file *protogen.File, o *options) (*protogen.GeneratedFile, error) {
 	if len(file.Services) == 0 {
-		return nil
+		return nil, nil
+	}
+
+	services, err := fileServices(file, o)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(services) == 0 {
+		// there are no methods with HTTP bindings or authorization policies
+		return nil, nil
 	}
 
 	filename := file.GeneratedFilenamePrefix + "_rest.pb.go"
-	g := gen.NewGeneratedFile(filename, file.GoImportPath)
+	packageName := file.GoPackageName
+	importPath := file.GoImportPath
+
+	if o.standalone {
+		// separate package in subdirectory of the package of proto messages
+		packageName += "rest"
+		importPath = protogen.GoImportPath(path.Join(string(importPath), string(packageName)))
+		filename = path.Join(path.Dir(file.GeneratedFilenamePrefix), string(packageName),
+			path.Base(file.GeneratedFilenamePrefix)+"_rest.pb.go")
+	}
+
+	g := gen.NewGeneratedFile(filename, importPath)
 	g.P("// Code generated by protoc-gen-go-rest. DO NOT EDIT.")
 	g.P("// versions:")
-	g.P("// - protoc-gen-go-rest v", _version)
+	g.P("// - protoc-gen-go-rest ", _version)
 	g.P("// - protoc             ", protocVersion(gen))
 
 	if file.Proto.GetOptions().GetDeprecated() {
@@ -26,11 +60,16 @@ func generateFile(gen *protogen.Plugin, file *protogen.File) *protogen.Generated
 	}
 
 	g.P()
-	g.P("package ", file.GoPackageName)
+
+	if !o.omitPackageDoc {
+		g.P("// Package ", packageName, " contains REST handlers of services defined in ", file.Desc.Path(), ".")
+	}
+
+	g.P("package ", packageName)
 	g.P()
-	generateFileContent(gen, file, g)
+	generateFileContent(gen, file, g, services, o)
 
-	return g
+	return g, nil
 }
 
 func protocVersion(gen *protogen.Plugin) string {
@@ -47,12 +86,369 @@ func protocVersion(gen *protogen.Plugin) string {
 	return fmt.Sprintf("v%d.%d.%d%s", v.GetMajor(), v.GetMinor(), v.GetPatch(), suffix)
 }
 
-// generateFileContent generates the gRPC service definitions, excluding the package statement.
-func generateFileContent(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile) {
-	for _, service := range file.Services {
-		genService(gen, file, g, service)
+// service is proto service with methods bound to HTTP routes. Services without HTTP bindings
+// have no methods, only their authorization policies are generated.
+type service struct {
+	*protogen.Service
+	methods []*method
+}
+
+// method is proto method with its HTTP bindings.
+type method struct {
+	*protogen.Method
+	bindings []*binding
+}
+
+// hasUpdateMask reports whether any binding of the method populates update mask.
+func (m *method) hasUpdateMask() bool {
+	for _, b := range m.bindings {
+		if len(b.updateMask) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isStreamingSupported reports whether method is unary or its streamed messages are google.api.HttpBody,
+// i.e. request body is streamed as chunks to client stream and server stream is written to response body.
+func isStreamingSupported(m *protogen.Method) bool {
+	if m.Desc.IsStreamingClient() && m.Input.Desc.FullName() != httpBodyName {
+		return false
+	}
+
+	if m.Desc.IsStreamingServer() && m.Output.Desc.FullName() != httpBodyName {
+		return false
+	}
+
+	return true
+}
+
+// fileServices returns services of the file that have methods with HTTP bindings or (rest.auth) option.
+func fileServices(file *protogen.File, o *options) ([]*service, error) {
+	var ss []*service
+
+	for _, s := range file.Services {
+		var mm []*method
+
+		for _, m := range s.Methods {
+			if !isStreamingSupported(m) {
+				// only streams of google.api.HttpBody are supported
+				if methodHTTPRule(m, o) != nil {
+					fmt.Fprintf(stderr, "protoc-gen-go-rest: warning: method '%s' is skipped, only streams of %s are supported\n",
+						m.Desc.FullName(), httpBodyName)
+				}
+
+				continue
+			}
+
+			bb, err := methodBindings(m, o)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(bb) > 0 {
+				mm = append(mm, &method{Method: m, bindings: bb})
+			} else if o.warnOnUnboundMethods {
+				fmt.Fprintf(stderr, "protoc-gen-go-rest: warning: method '%s' has no HTTP binding\n", m.Desc.FullName())
+			}
+		}
+
+		if len(mm) > 0 || len(servicePolicies(s)) > 0 {
+			ss = append(ss, &service{Service: s, methods: mm})
+		}
+	}
+
+	return ss, nil
+}
+
+// generateFileContent generates the REST service definitions, excluding the package statement.
+func generateFileContent(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, services []*service, o *options) {
+	for _, s := range services {
+		genService(gen, file, g, s, o)
+	}
+}
+
+func genService(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, s *service, o *options) {
+	genPolicies(g, s, o)
+
+	if len(s.methods) == 0 {
+		return
+	}
+
+	serverType := g.QualifiedGoIdent(protogen.GoIdent{
+		GoName:       s.GoName + "Server",
+		GoImportPath: file.GoImportPath,
+	})
+	registerName := "Register" + s.GoName + o.registerFuncSuffix + "Server"
+
+	g.P("// ", registerName, " registers REST routes of ", s.GoName, " service in the map.")
+	g.P("// Routes call srv in-process through interceptors of the map and opts.")
+
+	if s.Desc.Options().(*descriptorpb.ServiceOptions).GetDeprecated() {
+		g.P("//")
+		g.P("// Deprecated: Do not use.")
+	}
+
+	g.P("func ", registerName, "(m *", restPackage.Ident("Map"), ", srv ", serverType,
+		", opts ...", restPackage.Ident("RouteOption"), ") error {")
+
+	for _, m := range s.methods {
+		for _, b := range m.bindings {
+			args := strconv.Quote(b.body) + ", " + strconv.Quote(b.responseBody)
+			if m.hasUpdateMask() {
+				args += ", " + strconv.Quote(b.updateMask)
+			}
+
+			g.P("if err := m.Add(", strconv.Quote(b.method), ", ", strconv.Quote(b.template), ", ",
+				handlerName(s, m), "(srv, ", args, "),")
+			g.P("append([]", restPackage.Ident("RouteOption"), "{", restPackage.Ident("WithRPC"), "(",
+				strconv.Quote(string(s.Desc.FullName())), ", ", strconv.Quote(string(m.Desc.Name())), ")", routeOptions(g, s, m, o), "}, opts...)...); err != nil {")
+			g.P("return err")
+			g.P("}")
+			g.P()
+		}
+	}
+
+	g.P("return nil")
+	g.P("}")
+	g.P()
+
+	for _, m := range s.methods {
+		genHandler(g, serverType, s, m)
 	}
 }
 
-func genService(gen *protogen.Plugin, file *protogen.File, g *protogen.GeneratedFile, service *protogen.Service) {
+// routeOptions returns route options of the generated routes that follow RPC option.
+func routeOptions(g *protogen.GeneratedFile, s *service, m *method, o *options) string {
+	var ro string
+
+	if !o.jsonNamesForFields {
+		ro += ", " + g.QualifiedGoIdent(restPackage.Ident("WithMarshaler")) + "(" +
+			g.QualifiedGoIdent(restPackage.Ident("ProtoNamesMarshaler")) + ")"
+	}
+
+	if l := methodLimits(m); len(l) > 0 {
+		ro += ", " + g.QualifiedGoIdent(restPackage.Ident("WithLimits")) + "(" +
+			g.QualifiedGoIdent(restPackage.Ident("Limits")) + "{" + l + "})"
+	}
+
+	if len(methodScopes(m.Method)) > 0 {
+		ro += ", " + g.QualifiedGoIdent(restPackage.Ident("WithPolicy")) + "(" +
+			policiesName(s, o) + "[" + strconv.Quote(fullMethod(s, m.Method)) + "])"
+	}
+
+	if l := methodRateLimit(m); len(l) > 0 {
+		ro += ", " + g.QualifiedGoIdent(restPackage.Ident("WithRateLimit")) + "(" +
+			g.QualifiedGoIdent(restPackage.Ident("RateLimit")) + "{" + l + "})"
+	}
+
+	return ro
+}
+
+// methodScopes returns scopes of (rest.auth) option of the method.
+func methodScopes(m *protogen.Method) []string {
+	a, ok := proto.GetExtension(m.Desc.Options(), rest.E_Auth).(*rest.Auth)
+	if !ok || a == nil {
+		return nil
+	}
+
+	return a.GetScopes()
+}
+
+func fullMethod(s *service, m *protogen.Method) string {
+	return "/" + string(s.Desc.FullName()) + "/" + string(m.Desc.Name())
+}
+
+func policiesName(s *service, o *options) string {
+	return s.GoName + o.registerFuncSuffix + "Policies"
+}
+
+// genPolicies generates table of authorization policies of methods of the service with (rest.auth) option.
+// Methods without HTTP bindings are included, so the table also serves native gRPC interceptors.
+func genPolicies(g *protogen.GeneratedFile, s *service, o *options) {
+	mm := servicePolicies(s.Service)
+	if len(mm) == 0 {
+		return
+	}
+
+	g.P("// ", policiesName(s, o), " are authorization policies of ", s.GoName, " service methods from (rest.auth) options.")
+
+	if len(s.methods) > 0 {
+		g.P("// Routes registered by Register", s.GoName, o.registerFuncSuffix, "Server check them, use")
+		g.P("// UnaryServerInterceptor and StreamServerInterceptor to check them for native gRPC requests.")
+	} else {
+		g.P("// The service has no HTTP bindings, use UnaryServerInterceptor and StreamServerInterceptor")
+		g.P("// to check them for gRPC requests.")
+	}
+
+	g.P("var ", policiesName(s, o), " = ", restPackage.Ident("Policies"), "{")
+
+	for _, m := range mm {
+		scopes := make([]string, 0, len(methodScopes(m)))
+		for _, sc := range methodScopes(m) {
+			scopes = append(scopes, strconv.Quote(sc))
+		}
+
+		g.P(strconv.Quote(fullMethod(s, m)), ": {Scopes: []string{", strings.Join(scopes, ", "), "}},")
+	}
+
+	g.P("}")
+	g.P()
+}
+
+// servicePolicies returns methods of the service with (rest.auth) option.
+func servicePolicies(s *protogen.Service) []*protogen.Method {
+	var mm []*protogen.Method
+
+	for _, m := range s.Methods {
+		if len(methodScopes(m)) > 0 {
+			mm = append(mm, m)
+		}
+	}
+
+	return mm
+}
+
+// methodLimits returns fields of Limits literal from (rest.limits) option of the method,
+// empty string if the option is not set.
+func methodLimits(m *method) string {
+	l, ok := proto.GetExtension(m.Desc.Options(), rest.E_Limits).(*rest.Limits)
+	if !ok || l == nil {
+		return ""
+	}
+
+	var ff []string
+
+	if l.GetMaxBodySize() != 0 {
+		ff = append(ff, "MaxBodySize: "+strconv.FormatInt(l.GetMaxBodySize(), 10))
+	}
+
+	if l.GetMaxDepth() != 0 {
+		ff = append(ff, "MaxDepth: "+strconv.Itoa(int(l.GetMaxDepth())))
+	}
+
+	if l.GetMaxRepeated() != 0 {
+		ff = append(ff, "MaxRepeated: "+strconv.Itoa(int(l.GetMaxRepeated())))
+	}
+
+	return strings.Join(ff, ", ")
+}
+
+// methodRateLimit returns fields of RateLimit literal of (rest.rate_limit) option of the method.
+func methodRateLimit(m *method) string {
+	l, ok := proto.GetExtension(m.Desc.Options(), rest.E_RateLimit).(*rest.RateLimit)
+	if !ok || l == nil {
+		return ""
+	}
+
+	ff := []string{"Rate: " + strconv.FormatFloat(l.GetRate(), 'g', -1, 64)}
+
+	if l.GetBurst() != 0 {
+		ff = append(ff, "Burst: "+strconv.Itoa(int(l.GetBurst())))
+	}
+
+	return strings.Join(ff, ", ")
+}
+
+func handlerName(s *service, m *method) string {
+	return "_" + s.GoName + "_" + m.GoName + "_RESTHandler"
+}
+
+func genHandler(g *protogen.GeneratedFile, serverType string, s *service, m *method) {
+	params := "body string, responseBody string"
+	decodeArgs := "ctx, r, in, body"
+
+	if m.hasUpdateMask() {
+		params += ", updateMask string"
+		decodeArgs += ", " + g.QualifiedGoIdent(restPackage.Ident("WithUpdateMask")) + "(updateMask)"
+	}
+
+	g.P("func ", handlerName(s, m), "(srv ", serverType, ", ", params, ") ", restPackage.Ident("Handler"), " {")
+	g.P("return func(ctx ", contextPackage.Ident("Context"), ", w ", httpPackage.Ident("ResponseWriter"),
+		", r *", httpPackage.Ident("Request"), ") {")
+	g.P("ctx = ", restPackage.Ident("AnnotateContext"), "(ctx, r)")
+	g.P()
+
+	if !m.Desc.IsStreamingClient() {
+		g.P("in := new(", m.Input.GoIdent, ")")
+		g.P("if err := ", restPackage.Ident("DecodeRequest"), "(", decodeArgs, "); err != nil {")
+		g.P(restPackage.Ident("WriteError"), "(ctx, w, r, err)")
+		g.P()
+		g.P("return")
+		g.P("}")
+		g.P()
+	}
+
+	if m.Desc.IsStreamingClient() || m.Desc.IsStreamingServer() {
+		genStreamCall(g, serverType, s, m)
+
+		return
+	}
+
+	g.P("out, err := ", restPackage.Ident("Invoke"), "(ctx, srv, in, func(ctx ", contextPackage.Ident("Context"),
+		", req interface{}) (interface{}, error) {")
+	g.P("return srv.", m.GoName, "(ctx, req.(*", m.Input.GoIdent, "))")
+	g.P("})")
+	g.P("if err != nil {")
+	g.P(restPackage.Ident("WriteError"), "(ctx, w, r, err)")
+	g.P()
+	g.P("return")
+	g.P("}")
+	g.P()
+	g.P(restPackage.Ident("WriteResponse"), "(ctx, w, r, out.(*", m.Output.GoIdent, "), responseBody)")
+	g.P("}")
+	g.P("}")
+	g.P()
+}
+
+// genStreamCall generates call of streaming method with stream of the HTTP request and response
+// and the stream type that implements stream interface of the method generated by protoc-gen-go-grpc.
+func genStreamCall(g *protogen.GeneratedFile, serverType string, s *service, m *method) {
+	streamType := "_" + s.GoName + "_" + m.GoName + "_RESTStream"
+
+	g.P("stream := ", restPackage.Ident("NewServerStream"), "(ctx, w, r, responseBody)")
+	g.P("err := ", restPackage.Ident("InvokeStream"), "(srv, stream, ", m.Desc.IsStreamingClient(), ", ",
+		m.Desc.IsStreamingServer(), ", func(srv interface{}, ss ", grpcPackage.Ident("ServerStream"), ") error {")
+
+	if m.Desc.IsStreamingClient() {
+		g.P("return srv.(", serverType, ").", m.GoName, "(&", streamType, "{ss})")
+	} else {
+		g.P("return srv.(", serverType, ").", m.GoName, "(in, &", streamType, "{ss})")
+	}
+
+	g.P("})")
+	g.P("stream.Finish(err)")
+	g.P("}")
+	g.P("}")
+	g.P()
+
+	g.P("type ", streamType, " struct {")
+	g.P(grpcPackage.Ident("ServerStream"))
+	g.P("}")
+	g.P()
+
+	if m.Desc.IsStreamingServer() {
+		g.P("func (x *", streamType, ") Send(m *", m.Output.GoIdent, ") error {")
+		g.P("return x.ServerStream.SendMsg(m)")
+		g.P("}")
+		g.P()
+	} else {
+		g.P("func (x *", streamType, ") SendAndClose(m *", m.Output.GoIdent, ") error {")
+		g.P("return x.ServerStream.SendMsg(m)")
+		g.P("}")
+		g.P()
+	}
+
+	if m.Desc.IsStreamingClient() {
+		g.P("func (x *", streamType, ") Recv() (*", m.Input.GoIdent, ", error) {")
+		g.P("m := new(", m.Input.GoIdent, ")")
+		g.P("if err := x.ServerStream.RecvMsg(m); err != nil {")
+		g.P("return nil, err")
+		g.P("}")
+		g.P()
+		g.P("return m, nil")
+		g.P("}")
+		g.P()
+	}
 }
@@ -0,0 +1,50 @@
+// Code generated by protoc-gen-go-rest. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-rest v0.1.0
+// - protoc             (unknown)
+// source: deletebody/v1/delete_body.proto
+
+// Package deletebody contains REST handlers of services defined in deletebody/v1/delete_body.proto.
+package deletebody
+
+import (
+	context "context"
+	http "github.com/amsokol/protobuf-rest/runtime/http"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	http1 "net/http"
+)
+
+// RegisterShelfServiceRESTServer registers REST routes of ShelfService service in the map.
+// Routes call srv in-process through interceptors of the map and opts.
+func RegisterShelfServiceRESTServer(m *http.Map, srv ShelfServiceServer, opts ...http.RouteOption) error {
+	if err := m.Add("DELETE", "/v1/shelves", _ShelfService_DeleteShelves_RESTHandler(srv, "*", ""),
+		append([]http.RouteOption{http.WithRPC("example.deletebody.v1.ShelfService", "DeleteShelves")}, opts...)...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func _ShelfService_DeleteShelves_RESTHandler(srv ShelfServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(DeleteShelvesRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.DeleteShelves(ctx, req.(*DeleteShelvesRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*emptypb.Empty), responseBody)
+	}
+}
@@ -0,0 +1,113 @@
+// Code generated by protoc-gen-go-rest. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-rest v0.1.0
+// - protoc             (unknown)
+// source: library/v1/library.proto
+
+// Package library contains REST handlers of services defined in library/v1/library.proto.
+package library
+
+import (
+	context "context"
+	http "github.com/amsokol/protobuf-rest/runtime/http"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	http1 "net/http"
+)
+
+// RegisterLibraryServiceRESTServer registers REST routes of LibraryService service in the map.
+// Routes call srv in-process through interceptors of the map and opts.
+func RegisterLibraryServiceRESTServer(m *http.Map, srv LibraryServiceServer, opts ...http.RouteOption) error {
+	if err := m.Add("GET", "/v1/{name=shelves/*/books/*}", _LibraryService_GetBook_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("example.library.v1.LibraryService", "GetBook")}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("PATCH", "/v1/{book.name=shelves/*/books/*}", _LibraryService_UpdateBook_RESTHandler(srv, "book", ""),
+		append([]http.RouteOption{http.WithRPC("example.library.v1.LibraryService", "UpdateBook")}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("PUT", "/v1/{book.name=shelves/*/books/*}", _LibraryService_UpdateBook_RESTHandler(srv, "book", ""),
+		append([]http.RouteOption{http.WithRPC("example.library.v1.LibraryService", "UpdateBook")}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("DELETE", "/v1/{name=shelves/*/books/*}", _LibraryService_DeleteBook_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("example.library.v1.LibraryService", "DeleteBook")}, opts...)...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func _LibraryService_GetBook_RESTHandler(srv LibraryServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(GetBookRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.GetBook(ctx, req.(*GetBookRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*Book), responseBody)
+	}
+}
+
+func _LibraryService_UpdateBook_RESTHandler(srv LibraryServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(UpdateBookRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.UpdateBook(ctx, req.(*UpdateBookRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*Book), responseBody)
+	}
+}
+
+func _LibraryService_DeleteBook_RESTHandler(srv LibraryServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(DeleteBookRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.DeleteBook(ctx, req.(*DeleteBookRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*emptypb.Empty), responseBody)
+	}
+}
@@ -0,0 +1,121 @@
+// Code generated by protoc-gen-go-rest. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-rest v0.1.0
+// - protoc             (unknown)
+// source: auth/v1/books.proto
+
+// Package auth contains REST handlers of services defined in auth/v1/books.proto.
+package auth
+
+import (
+	context "context"
+	http "github.com/amsokol/protobuf-rest/runtime/http"
+	http1 "net/http"
+)
+
+// BookServiceRESTPolicies are authorization policies of BookService service methods from (rest.auth) options.
+// Routes registered by RegisterBookServiceRESTServer check them, use
+// UnaryServerInterceptor and StreamServerInterceptor to check them for native gRPC requests.
+var BookServiceRESTPolicies = http.Policies{
+	"/example.auth.v1.BookService/GetBook":    {Scopes: []string{"books.read"}},
+	"/example.auth.v1.BookService/UpdateBook": {Scopes: []string{"books.read", "books.write"}},
+	"/example.auth.v1.BookService/PurgeBooks": {Scopes: []string{"books.admin"}},
+}
+
+// RegisterBookServiceRESTServer registers REST routes of BookService service in the map.
+// Routes call srv in-process through interceptors of the map and opts.
+func RegisterBookServiceRESTServer(m *http.Map, srv BookServiceServer, opts ...http.RouteOption) error {
+	if err := m.Add("GET", "/v1/{name=books/*}", _BookService_GetBook_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("example.auth.v1.BookService", "GetBook"), http.WithPolicy(BookServiceRESTPolicies["/example.auth.v1.BookService/GetBook"])}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("GET", "/v1/{name=shelves/*/books/*}", _BookService_GetBook_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("example.auth.v1.BookService", "GetBook"), http.WithPolicy(BookServiceRESTPolicies["/example.auth.v1.BookService/GetBook"])}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("PUT", "/v1/{book.name=books/*}", _BookService_UpdateBook_RESTHandler(srv, "book", ""),
+		append([]http.RouteOption{http.WithRPC("example.auth.v1.BookService", "UpdateBook"), http.WithPolicy(BookServiceRESTPolicies["/example.auth.v1.BookService/UpdateBook"])}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("GET", "/v1/books", _BookService_ListBooks_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("example.auth.v1.BookService", "ListBooks")}, opts...)...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func _BookService_GetBook_RESTHandler(srv BookServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(GetBookRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.GetBook(ctx, req.(*GetBookRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*Book), responseBody)
+	}
+}
+
+func _BookService_UpdateBook_RESTHandler(srv BookServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(UpdateBookRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.UpdateBook(ctx, req.(*UpdateBookRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*Book), responseBody)
+	}
+}
+
+func _BookService_ListBooks_RESTHandler(srv BookServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(ListBooksRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.ListBooks(ctx, req.(*ListBooksRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*ListBooksResponse), responseBody)
+	}
+}
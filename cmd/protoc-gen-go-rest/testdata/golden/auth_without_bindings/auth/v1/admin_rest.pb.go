@@ -0,0 +1,19 @@
+// Code generated by protoc-gen-go-rest. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-rest v0.1.0
+// - protoc             (unknown)
+// source: auth/v1/admin.proto
+
+// Package auth contains REST handlers of services defined in auth/v1/admin.proto.
+package auth
+
+import (
+	http "github.com/amsokol/protobuf-rest/runtime/http"
+)
+
+// AdminServiceRESTPolicies are authorization policies of AdminService service methods from (rest.auth) options.
+// The service has no HTTP bindings, use UnaryServerInterceptor and StreamServerInterceptor
+// to check them for gRPC requests.
+var AdminServiceRESTPolicies = http.Policies{
+	"/example.auth.v1.AdminService/PurgeShelves": {Scopes: []string{"books.admin"}},
+}
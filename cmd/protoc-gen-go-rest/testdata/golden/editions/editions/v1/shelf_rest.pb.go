@@ -0,0 +1,78 @@
+// Code generated by protoc-gen-go-rest. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-rest v0.1.0
+// - protoc             (unknown)
+// source: editions/v1/shelf.proto
+
+// Package editions contains REST handlers of services defined in editions/v1/shelf.proto.
+package editions
+
+import (
+	context "context"
+	http "github.com/amsokol/protobuf-rest/runtime/http"
+	http1 "net/http"
+)
+
+// RegisterShelfServiceRESTServer registers REST routes of ShelfService service in the map.
+// Routes call srv in-process through interceptors of the map and opts.
+func RegisterShelfServiceRESTServer(m *http.Map, srv ShelfServiceServer, opts ...http.RouteOption) error {
+	if err := m.Add("GET", "/v1/{name=shelves/*}", _ShelfService_GetShelf_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("example.editions.v1.ShelfService", "GetShelf")}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("PATCH", "/v1/{shelf.name=shelves/*}", _ShelfService_UpdateShelf_RESTHandler(srv, "shelf", "", "update_mask"),
+		append([]http.RouteOption{http.WithRPC("example.editions.v1.ShelfService", "UpdateShelf")}, opts...)...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func _ShelfService_GetShelf_RESTHandler(srv ShelfServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(GetShelfRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.GetShelf(ctx, req.(*GetShelfRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*Shelf), responseBody)
+	}
+}
+
+func _ShelfService_UpdateShelf_RESTHandler(srv ShelfServiceServer, body string, responseBody string, updateMask string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(UpdateShelfRequest)
+		if err := http.DecodeRequest(ctx, r, in, body, http.WithUpdateMask(updateMask)); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.UpdateShelf(ctx, req.(*UpdateShelfRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*Shelf), responseBody)
+	}
+}
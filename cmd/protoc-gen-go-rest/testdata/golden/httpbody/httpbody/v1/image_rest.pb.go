@@ -0,0 +1,180 @@
+// Code generated by protoc-gen-go-rest. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-rest v0.1.0
+// - protoc             (unknown)
+// source: httpbody/v1/image.proto
+
+// Package httpbody contains REST handlers of services defined in httpbody/v1/image.proto.
+package httpbody
+
+import (
+	context "context"
+	http "github.com/amsokol/protobuf-rest/runtime/http"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
+	grpc "google.golang.org/grpc"
+	http1 "net/http"
+)
+
+// RegisterImageServiceRESTServer registers REST routes of ImageService service in the map.
+// Routes call srv in-process through interceptors of the map and opts.
+func RegisterImageServiceRESTServer(m *http.Map, srv ImageServiceServer, opts ...http.RouteOption) error {
+	if err := m.Add("GET", "/v1/{name=images/*}:download", _ImageService_GetImage_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("example.httpbody.v1.ImageService", "GetImage")}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("PATCH", "/v1/{name=images/*}", _ImageService_UpdateImage_RESTHandler(srv, "content", ""),
+		append([]http.RouteOption{http.WithRPC("example.httpbody.v1.ImageService", "UpdateImage")}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("GET", "/v1/{name=images/*}:stream", _ImageService_DownloadImage_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("example.httpbody.v1.ImageService", "DownloadImage")}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("POST", "/v1/images:upload", _ImageService_UploadImage_RESTHandler(srv, "*", "name"),
+		append([]http.RouteOption{http.WithRPC("example.httpbody.v1.ImageService", "UploadImage")}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("POST", "/v1/images:convert", _ImageService_ConvertImage_RESTHandler(srv, "*", ""),
+		append([]http.RouteOption{http.WithRPC("example.httpbody.v1.ImageService", "ConvertImage")}, opts...)...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func _ImageService_GetImage_RESTHandler(srv ImageServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(GetImageRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.GetImage(ctx, req.(*GetImageRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*httpbody.HttpBody), responseBody)
+	}
+}
+
+func _ImageService_UpdateImage_RESTHandler(srv ImageServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(UpdateImageRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.UpdateImage(ctx, req.(*UpdateImageRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*Image), responseBody)
+	}
+}
+
+func _ImageService_DownloadImage_RESTHandler(srv ImageServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(GetImageRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		stream := http.NewServerStream(ctx, w, r, responseBody)
+		err := http.InvokeStream(srv, stream, false, true, func(srv interface{}, ss grpc.ServerStream) error {
+			return srv.(ImageServiceServer).DownloadImage(in, &_ImageService_DownloadImage_RESTStream{ss})
+		})
+		stream.Finish(err)
+	}
+}
+
+type _ImageService_DownloadImage_RESTStream struct {
+	grpc.ServerStream
+}
+
+func (x *_ImageService_DownloadImage_RESTStream) Send(m *httpbody.HttpBody) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ImageService_UploadImage_RESTHandler(srv ImageServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		stream := http.NewServerStream(ctx, w, r, responseBody)
+		err := http.InvokeStream(srv, stream, true, false, func(srv interface{}, ss grpc.ServerStream) error {
+			return srv.(ImageServiceServer).UploadImage(&_ImageService_UploadImage_RESTStream{ss})
+		})
+		stream.Finish(err)
+	}
+}
+
+type _ImageService_UploadImage_RESTStream struct {
+	grpc.ServerStream
+}
+
+func (x *_ImageService_UploadImage_RESTStream) SendAndClose(m *Image) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *_ImageService_UploadImage_RESTStream) Recv() (*httpbody.HttpBody, error) {
+	m := new(httpbody.HttpBody)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func _ImageService_ConvertImage_RESTHandler(srv ImageServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		stream := http.NewServerStream(ctx, w, r, responseBody)
+		err := http.InvokeStream(srv, stream, true, true, func(srv interface{}, ss grpc.ServerStream) error {
+			return srv.(ImageServiceServer).ConvertImage(&_ImageService_ConvertImage_RESTStream{ss})
+		})
+		stream.Finish(err)
+	}
+}
+
+type _ImageService_ConvertImage_RESTStream struct {
+	grpc.ServerStream
+}
+
+func (x *_ImageService_ConvertImage_RESTStream) Send(m *httpbody.HttpBody) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *_ImageService_ConvertImage_RESTStream) Recv() (*httpbody.HttpBody, error) {
+	m := new(httpbody.HttpBody)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
@@ -0,0 +1,107 @@
+// Code generated by protoc-gen-go-rest. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-rest v0.1.0
+// - protoc             (unknown)
+// source: upload/v1/upload.proto
+
+// Package upload contains REST handlers of services defined in upload/v1/upload.proto.
+package upload
+
+import (
+	context "context"
+	http "github.com/amsokol/protobuf-rest/runtime/http"
+	http1 "net/http"
+)
+
+// RegisterUploadServiceRESTServer registers REST routes of UploadService service in the map.
+// Routes call srv in-process through interceptors of the map and opts.
+func RegisterUploadServiceRESTServer(m *http.Map, srv UploadServiceServer, opts ...http.RouteOption) error {
+	if err := m.Add("POST", "/v1/files", _UploadService_CreateFile_RESTHandler(srv, "file", ""),
+		append([]http.RouteOption{http.WithRPC("example.upload.v1.UploadService", "CreateFile"), http.WithLimits(http.Limits{MaxBodySize: 67108864, MaxRepeated: -1})}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("POST", "/v1/files:batchCreate", _UploadService_CreateFiles_RESTHandler(srv, "*", ""),
+		append([]http.RouteOption{http.WithRPC("example.upload.v1.UploadService", "CreateFiles"), http.WithLimits(http.Limits{MaxDepth: 8, MaxRepeated: 100})}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("GET", "/v1/{name=files/*}", _UploadService_GetFile_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("example.upload.v1.UploadService", "GetFile")}, opts...)...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func _UploadService_CreateFile_RESTHandler(srv UploadServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(CreateFileRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.CreateFile(ctx, req.(*CreateFileRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*File), responseBody)
+	}
+}
+
+func _UploadService_CreateFiles_RESTHandler(srv UploadServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(CreateFilesRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.CreateFiles(ctx, req.(*CreateFilesRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*CreateFilesResponse), responseBody)
+	}
+}
+
+func _UploadService_GetFile_RESTHandler(srv UploadServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(GetFileRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.GetFile(ctx, req.(*GetFileRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*File), responseBody)
+	}
+}
@@ -0,0 +1,78 @@
+// Code generated by protoc-gen-go-rest. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-rest v0.1.0
+// - protoc             (unknown)
+// source: proto2/v1/shelf.proto
+
+// Package proto2 contains REST handlers of services defined in proto2/v1/shelf.proto.
+package proto2
+
+import (
+	context "context"
+	http "github.com/amsokol/protobuf-rest/runtime/http"
+	http1 "net/http"
+)
+
+// RegisterShelfServiceRESTServer registers REST routes of ShelfService service in the map.
+// Routes call srv in-process through interceptors of the map and opts.
+func RegisterShelfServiceRESTServer(m *http.Map, srv ShelfServiceServer, opts ...http.RouteOption) error {
+	if err := m.Add("GET", "/v1/{name=shelves/*}", _ShelfService_GetShelf_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("example.proto2.v1.ShelfService", "GetShelf")}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("POST", "/v1/shelves", _ShelfService_CreateShelf_RESTHandler(srv, "shelf", "name"),
+		append([]http.RouteOption{http.WithRPC("example.proto2.v1.ShelfService", "CreateShelf")}, opts...)...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func _ShelfService_GetShelf_RESTHandler(srv ShelfServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(GetShelfRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.GetShelf(ctx, req.(*GetShelfRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*Shelf), responseBody)
+	}
+}
+
+func _ShelfService_CreateShelf_RESTHandler(srv ShelfServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(CreateShelfRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.CreateShelf(ctx, req.(*CreateShelfRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*Shelf), responseBody)
+	}
+}
@@ -0,0 +1,112 @@
+// Code generated by protoc-gen-go-rest. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-rest v0.1.0
+// - protoc             (unknown)
+// source: ratelimit/v1/search.proto
+
+// Package ratelimit contains REST handlers of services defined in ratelimit/v1/search.proto.
+package ratelimit
+
+import (
+	context "context"
+	http "github.com/amsokol/protobuf-rest/runtime/http"
+	http1 "net/http"
+)
+
+// RegisterSearchServiceRESTServer registers REST routes of SearchService service in the map.
+// Routes call srv in-process through interceptors of the map and opts.
+func RegisterSearchServiceRESTServer(m *http.Map, srv SearchServiceServer, opts ...http.RouteOption) error {
+	if err := m.Add("GET", "/v1/search", _SearchService_Search_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("example.ratelimit.v1.SearchService", "Search"), http.WithRateLimit(http.RateLimit{Rate: 0.5, Burst: 5})}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("POST", "/v1/search", _SearchService_Search_RESTHandler(srv, "*", ""),
+		append([]http.RouteOption{http.WithRPC("example.ratelimit.v1.SearchService", "Search"), http.WithRateLimit(http.RateLimit{Rate: 0.5, Burst: 5})}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("GET", "/v1/suggest", _SearchService_Suggest_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("example.ratelimit.v1.SearchService", "Suggest"), http.WithRateLimit(http.RateLimit{Rate: 20})}, opts...)...); err != nil {
+		return err
+	}
+
+	if err := m.Add("GET", "/v1/status", _SearchService_Status_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("example.ratelimit.v1.SearchService", "Status")}, opts...)...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func _SearchService_Search_RESTHandler(srv SearchServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(SearchRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.Search(ctx, req.(*SearchRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*SearchResponse), responseBody)
+	}
+}
+
+func _SearchService_Suggest_RESTHandler(srv SearchServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(SearchRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.Suggest(ctx, req.(*SearchRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*SearchResponse), responseBody)
+	}
+}
+
+func _SearchService_Status_RESTHandler(srv SearchServiceServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(StatusRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.Status(ctx, req.(*StatusRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		http.WriteResponse(ctx, w, r, out.(*StatusResponse), responseBody)
+	}
+}
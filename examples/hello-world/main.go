@@ -1,23 +1,48 @@
 package main
 
 import (
+	"context"
 	"log"
-	"net/http"
+
+	"github.com/amsokol/protobuf-rest/examples/hello-world/proto"
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/grpc"
 )
 
+type greeterServer struct {
+	proto.UnimplementedGreeterServer
+}
+
+func (g *greeterServer) SayHello(ctx context.Context, req *proto.HelloRequest) (*proto.HelloReply, error) {
+	return &proto.HelloReply{Message: "Hello " + req.GetName()}, nil
+}
+
+// logging is gRPC interceptor, it is called for REST calls the same way as for gRPC calls.
+func logging(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	log.Printf("call %s", info.FullMethod)
+
+	return handler(ctx, req)
+}
+
 func main() {
-	s := &http.Server{
-		Addr:    ":8080",
-		Handler: &Server{},
-		// ReadTimeout:    10 * time.Second,
-		// WriteTimeout:   10 * time.Second,
-		// MaxHeaderBytes: 1 << 20,
+	interceptors := _http.GRPCInterceptors{
+		Unary: []grpc.UnaryServerInterceptor{logging},
 	}
-	log.Fatal(s.ListenAndServe())
-}
 
-type Server struct{}
+	srv := &greeterServer{}
 
-func (s *Server) ServeHTTP(http.ResponseWriter, *http.Request) {
+	gs := grpc.NewServer(interceptors.ServerOptions()...)
+	proto.RegisterGreeterServer(gs, srv)
 
+	m := _http.NewMap()
+
+	if err := proto.RegisterGreeterRESTServer(m, srv, interceptors.RouteOption()); err != nil {
+		log.Fatal(err)
+	}
+
+	// gRPC and REST are served on the same port, gRPC clients connect with h2c
+	s := _http.NewServer(":8080", gs, m)
+
+	log.Println("Serving gRPC and REST on http://0.0.0.0:8080")
+	log.Fatal(s.ListenAndServe())
 }
@@ -1,9 +1,49 @@
+// Code generated by protoc-gen-go-rest. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-rest v0.1.0
+// - protoc             v3.17.3
+// source: hello_world.proto
+
+// Package proto contains REST handlers of services defined in hello_world.proto.
 package proto
 
-import "context"
+import (
+	context "context"
+	http "github.com/amsokol/protobuf-rest/runtime/http"
+	http1 "net/http"
+)
+
+// RegisterGreeterRESTServer registers REST routes of Greeter service in the map.
+// Routes call srv in-process through interceptors of the map and opts.
+func RegisterGreeterRESTServer(m *http.Map, srv GreeterServer, opts ...http.RouteOption) error {
+	if err := m.Add("POST", "/v1/example/echo/{name}", _Greeter_SayHello_RESTHandler(srv, "", ""),
+		append([]http.RouteOption{http.WithRPC("proto.Greeter", "SayHello")}, opts...)...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func _Greeter_SayHello_RESTHandler(srv GreeterServer, body string, responseBody string) http.Handler {
+	return func(ctx context.Context, w http1.ResponseWriter, r *http1.Request) {
+		ctx = http.AnnotateContext(ctx, r)
+
+		in := new(HelloRequest)
+		if err := http.DecodeRequest(ctx, r, in, body); err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
+
+		out, err := http.Invoke(ctx, srv, in, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return srv.SayHello(ctx, req.(*HelloRequest))
+		})
+		if err != nil {
+			http.WriteError(ctx, w, r, err)
+
+			return
+		}
 
-// GreeterServer is the server API for Greeter service.
-type GreeterServer interface {
-	// Sends a greeting
-	SayHello(context.Context, *HelloRequest) (*HelloReply, error)
+		http.WriteResponse(ctx, w, r, out.(*HelloReply), responseBody)
+	}
 }
@@ -0,0 +1,294 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: rest/options.proto
+
+package rest
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Limits struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxBodySize   int64                  `protobuf:"varint,1,opt,name=max_body_size,json=maxBodySize,proto3" json:"max_body_size,omitempty"`
+	MaxDepth      int32                  `protobuf:"varint,2,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	MaxRepeated   int32                  `protobuf:"varint,3,opt,name=max_repeated,json=maxRepeated,proto3" json:"max_repeated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Limits) Reset() {
+	*x = Limits{}
+	mi := &file_rest_options_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Limits) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Limits) ProtoMessage() {}
+
+func (x *Limits) ProtoReflect() protoreflect.Message {
+	mi := &file_rest_options_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Limits.ProtoReflect.Descriptor instead.
+func (*Limits) Descriptor() ([]byte, []int) {
+	return file_rest_options_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Limits) GetMaxBodySize() int64 {
+	if x != nil {
+		return x.MaxBodySize
+	}
+	return 0
+}
+
+func (x *Limits) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *Limits) GetMaxRepeated() int32 {
+	if x != nil {
+		return x.MaxRepeated
+	}
+	return 0
+}
+
+type Auth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Scopes        []string               `protobuf:"bytes,1,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Auth) Reset() {
+	*x = Auth{}
+	mi := &file_rest_options_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Auth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Auth) ProtoMessage() {}
+
+func (x *Auth) ProtoReflect() protoreflect.Message {
+	mi := &file_rest_options_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Auth.ProtoReflect.Descriptor instead.
+func (*Auth) Descriptor() ([]byte, []int) {
+	return file_rest_options_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Auth) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+type RateLimit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rate          float64                `protobuf:"fixed64,1,opt,name=rate,proto3" json:"rate,omitempty"`
+	Burst         int32                  `protobuf:"varint,2,opt,name=burst,proto3" json:"burst,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateLimit) Reset() {
+	*x = RateLimit{}
+	mi := &file_rest_options_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimit) ProtoMessage() {}
+
+func (x *RateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_rest_options_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimit.ProtoReflect.Descriptor instead.
+func (*RateLimit) Descriptor() ([]byte, []int) {
+	return file_rest_options_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RateLimit) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *RateLimit) GetBurst() int32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
+var file_rest_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*Limits)(nil),
+		Field:         50700,
+		Name:          "rest.limits",
+		Tag:           "bytes,50700,opt,name=limits",
+		Filename:      "rest/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*Auth)(nil),
+		Field:         50701,
+		Name:          "rest.auth",
+		Tag:           "bytes,50701,opt,name=auth",
+		Filename:      "rest/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.MethodOptions)(nil),
+		ExtensionType: (*RateLimit)(nil),
+		Field:         50702,
+		Name:          "rest.rate_limit",
+		Tag:           "bytes,50702,opt,name=rate_limit",
+		Filename:      "rest/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.MethodOptions.
+var (
+	// optional rest.Limits limits = 50700;
+	E_Limits = &file_rest_options_proto_extTypes[0]
+	// optional rest.Auth auth = 50701;
+	E_Auth = &file_rest_options_proto_extTypes[1]
+	// optional rest.RateLimit rate_limit = 50702;
+	E_RateLimit = &file_rest_options_proto_extTypes[2]
+)
+
+var File_rest_options_proto protoreflect.FileDescriptor
+
+const file_rest_options_proto_rawDesc = "" +
+	"\n" +
+	"\x12rest/options.proto\x12\x04rest\x1a google/protobuf/descriptor.proto\"l\n" +
+	"\x06Limits\x12\"\n" +
+	"\rmax_body_size\x18\x01 \x01(\x03R\vmaxBodySize\x12\x1b\n" +
+	"\tmax_depth\x18\x02 \x01(\x05R\bmaxDepth\x12!\n" +
+	"\fmax_repeated\x18\x03 \x01(\x05R\vmaxRepeated\"\x1e\n" +
+	"\x04Auth\x12\x16\n" +
+	"\x06scopes\x18\x01 \x03(\tR\x06scopes\"5\n" +
+	"\tRateLimit\x12\x12\n" +
+	"\x04rate\x18\x01 \x01(\x01R\x04rate\x12\x14\n" +
+	"\x05burst\x18\x02 \x01(\x05R\x05burst:F\n" +
+	"\x06limits\x12\x1e.google.protobuf.MethodOptions\x18\x8c\x8c\x03 \x01(\v2\f.rest.LimitsR\x06limits:@\n" +
+	"\x04auth\x12\x1e.google.protobuf.MethodOptions\x18\x8d\x8c\x03 \x01(\v2\n" +
+	".rest.AuthR\x04auth:P\n" +
+	"\n" +
+	"rate_limit\x12\x1e.google.protobuf.MethodOptions\x18\x8e\x8c\x03 \x01(\v2\x0f.rest.RateLimitR\trateLimitB,Z*github.com/amsokol/protobuf-rest/rest;restb\x06proto3"
+
+var (
+	file_rest_options_proto_rawDescOnce sync.Once
+	file_rest_options_proto_rawDescData []byte
+)
+
+func file_rest_options_proto_rawDescGZIP() []byte {
+	file_rest_options_proto_rawDescOnce.Do(func() {
+		file_rest_options_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_rest_options_proto_rawDesc), len(file_rest_options_proto_rawDesc)))
+	})
+	return file_rest_options_proto_rawDescData
+}
+
+var file_rest_options_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_rest_options_proto_goTypes = []any{
+	(*Limits)(nil),                     // 0: rest.Limits
+	(*Auth)(nil),                       // 1: rest.Auth
+	(*RateLimit)(nil),                  // 2: rest.RateLimit
+	(*descriptorpb.MethodOptions)(nil), // 3: google.protobuf.MethodOptions
+}
+var file_rest_options_proto_depIdxs = []int32{
+	3, // 0: rest.limits:extendee -> google.protobuf.MethodOptions
+	3, // 1: rest.auth:extendee -> google.protobuf.MethodOptions
+	3, // 2: rest.rate_limit:extendee -> google.protobuf.MethodOptions
+	0, // 3: rest.limits:type_name -> rest.Limits
+	1, // 4: rest.auth:type_name -> rest.Auth
+	2, // 5: rest.rate_limit:type_name -> rest.RateLimit
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	3, // [3:6] is the sub-list for extension type_name
+	0, // [0:3] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_rest_options_proto_init() }
+func file_rest_options_proto_init() {
+	if File_rest_options_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_rest_options_proto_rawDesc), len(file_rest_options_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 3,
+			NumServices:   0,
+		},
+		GoTypes:           file_rest_options_proto_goTypes,
+		DependencyIndexes: file_rest_options_proto_depIdxs,
+		MessageInfos:      file_rest_options_proto_msgTypes,
+		ExtensionInfos:    file_rest_options_proto_extTypes,
+	}.Build()
+	File_rest_options_proto = out.File
+	file_rest_options_proto_goTypes = nil
+	file_rest_options_proto_depIdxs = nil
+}
@@ -0,0 +1,88 @@
+package runtime
+
+import "sync"
+
+// Captures is buffer of field values captured by Segment.MatchPath.
+// Values are kept as offsets into url path, so capturing does not allocate.
+// Captures may be reused, see AcquireCaptures and ReleaseCaptures.
+type Captures struct {
+	path string
+	cc   []capture
+}
+
+type capture struct {
+	field      string
+	start, end int // value is path[start:end]
+}
+
+var _capturesPool = sync.Pool{
+	New: func() interface{} {
+		return &Captures{cc: make([]capture, 0, 8)}
+	},
+}
+
+// AcquireCaptures returns empty Captures from the pool.
+func AcquireCaptures() *Captures {
+	return _capturesPool.Get().(*Captures)
+}
+
+// ReleaseCaptures returns Captures to the pool, c must not be used after that.
+func ReleaseCaptures(c *Captures) {
+	c.Reset("")
+	_capturesPool.Put(c)
+}
+
+// Reset clears captured values and sets url path values are captured from.
+func (c *Captures) Reset(path string) {
+	c.path = path
+	c.cc = c.cc[:0]
+}
+
+// Len returns number of captured fields.
+func (c *Captures) Len() int {
+	return len(c.cc)
+}
+
+// Field returns field name and value of i-th captured field.
+func (c *Captures) Field(i int) (string, string) {
+	f := c.cc[i]
+
+	return f.field, c.path[f.start:f.end]
+}
+
+// Get returns captured value of the field.
+func (c *Captures) Get(field string) (string, bool) {
+	for _, f := range c.cc {
+		if f.field == field {
+			return c.path[f.start:f.end], true
+		}
+	}
+
+	return "", false
+}
+
+// Values returns captured values as map.
+func (c *Captures) Values() Values {
+	vv := make(Values, len(c.cc))
+	for _, f := range c.cc {
+		vv[f.field] = c.path[f.start:f.end]
+	}
+
+	return vv
+}
+
+// add captures value path[start:end] of the field. If extend is true
+// value is extended up to end, it is used for "{field=value}" pattern value.
+func (c *Captures) add(field string, start int, end int, extend bool) {
+	if extend {
+		for i := len(c.cc) - 1; i >= 0; i-- {
+			if c.cc[i].field == field {
+				c.cc[i].end = end
+
+				return
+			}
+		}
+	}
+
+	c.cc = append(c.cc, capture{field: field, start: start, end: end})
+}
@@ -0,0 +1,51 @@
+package runtime_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/amsokol/protobuf-rest/runtime"
+)
+
+func TestCaptures(t *testing.T) {
+	p, err := runtime.NewPath("/v1/books/{name=shelves/*/books/*}/pages/{page}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := runtime.AcquireCaptures()
+	defer runtime.ReleaseCaptures(c)
+
+	if !p.MatchPath("v1/books/shelves/1/books/2/pages/3", c) {
+		t.Fatal("Segment.MatchPath() = false, want true")
+	}
+
+	if c.Len() != 2 {
+		t.Errorf("Captures.Len() = %v, want 2", c.Len())
+	}
+
+	if f, v := c.Field(0); f != "name" || v != "shelves/1/books/2" {
+		t.Errorf("Captures.Field(0) = %v, %v, want name, shelves/1/books/2", f, v)
+	}
+
+	if v, ok := c.Get("page"); !ok || v != "3" {
+		t.Errorf("Captures.Get(page) = %v, %v, want 3, true", v, ok)
+	}
+
+	if _, ok := c.Get("unknown"); ok {
+		t.Error("Captures.Get(unknown) = true, want false")
+	}
+
+	want := runtime.Values{
+		"name": "shelves/1/books/2",
+		"page": "3",
+	}
+
+	if got := c.Values(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Captures.Values() = %v, want %v", got, want)
+	}
+
+	if p.MatchPath("v1/books/shelves/1/pages/3", c) {
+		t.Error("Segment.MatchPath() = true, want false")
+	}
+}
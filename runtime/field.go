@@ -0,0 +1,326 @@
+package runtime
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// FieldByPath returns descriptors of fields of message m by field path, e.g. "book.author.name".
+// Path elements may be proto or JSON field names and are compared case-insensitively.
+// Extension fields are referenced by full name in brackets, e.g. "options.[google.api.field_behavior]",
+// extension types must be registered in protoregistry.GlobalTypes.
+func FieldByPath(m protoreflect.MessageDescriptor, fieldPath string) ([]protoreflect.FieldDescriptor, error) {
+	ff, err := splitFieldPath(fieldPath)
+	if err != nil {
+		return nil, err
+	}
+
+	fds := make([]protoreflect.FieldDescriptor, 0, len(ff))
+
+	for i, name := range ff {
+		if m == nil {
+			return nil, fmt.Errorf("%w: '%s': '%s' is not a message", ErrInvalidFieldPath, fieldPath, ff[i-1])
+		}
+
+		fd := findField(m, name)
+		if fd == nil {
+			return nil, fmt.Errorf("%w: '%s' in '%s'", ErrUnknownField, fieldPath, m.FullName())
+		}
+
+		fds = append(fds, fd)
+
+		m = nil
+		if fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+			m = fd.Message()
+		}
+	}
+
+	return fds, nil
+}
+
+// CanonicalFieldPath returns field path of message m composed of proto field names
+// and full names of extension fields in brackets.
+func CanonicalFieldPath(m protoreflect.MessageDescriptor, fieldPath string) (string, error) {
+	fds, err := FieldByPath(m, fieldPath)
+	if err != nil {
+		return "", err
+	}
+
+	ff := make([]string, len(fds))
+	for i, fd := range fds {
+		ff[i] = fieldName(fd)
+	}
+
+	return strings.Join(ff, "."), nil
+}
+
+// fieldName returns proto name of field or full name of extension field in brackets.
+func fieldName(fd protoreflect.FieldDescriptor) string {
+	if fd.IsExtension() {
+		return "[" + string(fd.FullName()) + "]"
+	}
+
+	return string(fd.Name())
+}
+
+// splitFieldPath splits field path by dots that are not part of extension names in brackets.
+func splitFieldPath(fieldPath string) ([]string, error) {
+	var (
+		ff    []string
+		start int
+		ext   bool
+	)
+
+	for i, c := range fieldPath {
+		switch {
+		case c == '[' && !ext && i == start:
+			ext = true
+		case c == ']' && ext:
+			ext = false
+		case c == '.' && !ext:
+			ff = append(ff, fieldPath[start:i])
+			start = i + 1
+		}
+	}
+
+	if ext {
+		return nil, fmt.Errorf("%w: '%s': unclosed bracket", ErrInvalidFieldPath, fieldPath)
+	}
+
+	return append(ff, fieldPath[start:]), nil
+}
+
+func findField(m protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if strings.HasPrefix(name, "[") && strings.HasSuffix(name, "]") {
+		return findExtension(m, name[1:len(name)-1])
+	}
+
+	ff := m.Fields()
+
+	if fd := ff.ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+
+	if fd := ff.ByJSONName(name); fd != nil {
+		return fd
+	}
+
+	for i := 0; i < ff.Len(); i++ {
+		fd := ff.Get(i)
+		if strings.EqualFold(string(fd.Name()), name) || strings.EqualFold(fd.JSONName(), name) {
+			return fd
+		}
+	}
+
+	return nil
+}
+
+// findExtension returns extension field of message m by full name from protoregistry.GlobalTypes.
+func findExtension(m protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	xt, err := protoregistry.GlobalTypes.FindExtensionByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil
+	}
+
+	if xd := xt.TypeDescriptor(); xd.ContainingMessage().FullName() == m.FullName() {
+		return xd
+	}
+
+	return nil
+}
+
+// PopulateField sets field of message m by field path from string values.
+// Repeated field gets all values, other fields get the last value.
+func PopulateField(m protoreflect.Message, fieldPath string, values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	fds, err := FieldByPath(m.Descriptor(), fieldPath)
+	if err != nil {
+		return err
+	}
+
+	for _, fd := range fds[:len(fds)-1] {
+		m = m.Mutable(fd).Message()
+	}
+
+	fd := fds[len(fds)-1]
+
+	switch {
+	case fd.IsMap():
+		return fmt.Errorf("%w: '%s' is map", ErrInvalidFieldPath, fieldPath)
+	case fd.IsList():
+		l := m.Mutable(fd).List()
+
+		for _, s := range values {
+			v, err := parseValue(m, fd, s)
+			if err != nil {
+				return fmt.Errorf("parse value of field '%s': %w", fieldPath, err)
+			}
+
+			l.Append(v)
+		}
+	default:
+		v, err := parseValue(m, fd, values[len(values)-1])
+		if err != nil {
+			return fmt.Errorf("parse value of field '%s': %w", fieldPath, err)
+		}
+
+		m.Set(fd, v)
+	}
+
+	return nil
+}
+
+// PopulateQuery sets fields of message m from query parameters.
+// Parameters of fields that are listed in skip (or nested in them) and unknown parameters are ignored.
+func PopulateQuery(m proto.Message, query url.Values, skip ...string) error {
+	md := m.ProtoReflect().Descriptor()
+
+	ss := make([]string, 0, len(skip))
+
+	for _, s := range skip {
+		if s == "*" {
+			// all fields are bound
+			return nil
+		}
+
+		if c, err := CanonicalFieldPath(md, s); err == nil {
+			ss = append(ss, c)
+		}
+	}
+
+	for k, vv := range query {
+		c, err := CanonicalFieldPath(md, k)
+		if err != nil {
+			if errors.Is(err, ErrUnknownField) {
+				continue
+			}
+
+			return err
+		}
+
+		if isSkipped(c, ss) {
+			continue
+		}
+
+		if err := PopulateField(m.ProtoReflect(), c, vv...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func isSkipped(fieldPath string, skip []string) bool {
+	for _, s := range skip {
+		if fieldPath == s || strings.HasPrefix(fieldPath, s+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseValue(m protoreflect.Message, fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(s)
+
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(s, 10, 32)
+
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(s, 10, 64)
+
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(s, 10, 32)
+
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(s, 10, 64)
+
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(s, 32)
+
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(s, 64)
+
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BytesKind:
+		v, err := parseBytes(s)
+
+		return protoreflect.ValueOfBytes(v), err
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(s)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+
+		v, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("%w: '%s' is not value of %s", ErrInvalidValue, s, fd.Enum().FullName())
+		}
+
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return parseMessage(m, fd, s)
+	default:
+		return protoreflect.Value{}, fmt.Errorf("%w: unsupported kind %s", ErrInvalidValue, fd.Kind())
+	}
+}
+
+func parseBytes(s string) ([]byte, error) {
+	for _, e := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if v, err := e.DecodeString(s); err == nil {
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: '%s' is not base64 encoded", ErrInvalidValue, s)
+}
+
+// parseMessage parses value of well-known type from its JSON representation,
+// e.g. google.protobuf.Timestamp from "2021-08-01T12:00:00Z".
+func parseMessage(m protoreflect.Message, fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	var v protoreflect.Value
+	if fd.IsList() {
+		v = m.Mutable(fd).List().NewElement()
+	} else {
+		v = m.NewField(fd)
+	}
+
+	// try string representation first, e.g. Timestamp, Duration, FieldMask
+	if err := protojson.Unmarshal([]byte(strconv.Quote(s)), v.Message().Interface()); err == nil {
+		return v, nil
+	}
+
+	// try JSON value, e.g. BoolValue, DoubleValue
+	if err := protojson.Unmarshal([]byte(s), v.Message().Interface()); err != nil {
+		return protoreflect.Value{}, fmt.Errorf("%w: '%s' is not value of %s", ErrInvalidValue, s, fd.Message().FullName())
+	}
+
+	return v, nil
+}
+
+var (
+	ErrUnknownField     = errors.New("unknown field")
+	ErrInvalidFieldPath = errors.New("invalid field path")
+	ErrInvalidValue     = errors.New("invalid field value")
+)
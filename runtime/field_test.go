@@ -0,0 +1,300 @@
+package runtime_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/amsokol/protobuf-rest/runtime"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/gofeaturespb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestPopulateField(t *testing.T) {
+	type args struct {
+		m         proto.Message
+		fieldPath string
+		values    []string
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    proto.Message
+		wantErr error
+	}{
+		{
+			"uint64",
+			args{
+				&descriptorpb.UninterpretedOption{},
+				"positive_int_value",
+				[]string{"42"},
+			},
+			&descriptorpb.UninterpretedOption{PositiveIntValue: proto.Uint64(42)},
+			nil,
+		},
+		{
+			"int64 by JSON name",
+			args{
+				&descriptorpb.UninterpretedOption{},
+				"negativeIntValue",
+				[]string{"-7"},
+			},
+			&descriptorpb.UninterpretedOption{NegativeIntValue: proto.Int64(-7)},
+			nil,
+		},
+		{
+			"double, last value",
+			args{
+				&descriptorpb.UninterpretedOption{},
+				"double_value",
+				[]string{"1", "1.5"},
+			},
+			&descriptorpb.UninterpretedOption{DoubleValue: proto.Float64(1.5)},
+			nil,
+		},
+		{
+			"bytes",
+			args{
+				&descriptorpb.UninterpretedOption{},
+				"string_value",
+				[]string{"aGVsbG8="},
+			},
+			&descriptorpb.UninterpretedOption{StringValue: []byte("hello")},
+			nil,
+		},
+		{
+			"enum by name",
+			args{
+				&descriptorpb.FieldDescriptorProto{},
+				"label",
+				[]string{"LABEL_REPEATED"},
+			},
+			&descriptorpb.FieldDescriptorProto{Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()},
+			nil,
+		},
+		{
+			"enum by number",
+			args{
+				&descriptorpb.FieldDescriptorProto{},
+				"type",
+				[]string{"9"},
+			},
+			&descriptorpb.FieldDescriptorProto{Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum()},
+			nil,
+		},
+		{
+			"nested",
+			args{
+				&annotations.HttpRule{},
+				"custom.kind",
+				[]string{"HEAD"},
+			},
+			&annotations.HttpRule{Pattern: &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Kind: "HEAD"}}},
+			nil,
+		},
+		{
+			"repeated",
+			args{
+				&fieldmaskpb.FieldMask{},
+				"paths",
+				[]string{"a", "b"},
+			},
+			&fieldmaskpb.FieldMask{Paths: []string{"a", "b"}},
+			nil,
+		},
+		{
+			"well-known type",
+			args{
+				&errdetails.RetryInfo{},
+				"retry_delay",
+				[]string{"1.5s"},
+			},
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(1500000000)},
+			nil,
+		},
+		{
+			"unknown field",
+			args{
+				&annotations.HttpRule{},
+				"custom.unknown",
+				[]string{"HEAD"},
+			},
+			nil,
+			runtime.ErrUnknownField,
+		},
+		{
+			"path through repeated field",
+			args{
+				&annotations.HttpRule{},
+				"additional_bindings.get",
+				[]string{"/v1"},
+			},
+			nil,
+			runtime.ErrInvalidFieldPath,
+		},
+		{
+			"invalid enum",
+			args{
+				&descriptorpb.FieldDescriptorProto{},
+				"label",
+				[]string{"LABEL_UNKNOWN"},
+			},
+			nil,
+			runtime.ErrInvalidValue,
+		},
+		{
+			"proto2 zero value presence",
+			args{
+				&descriptorpb.FieldOptions{},
+				"deprecated",
+				[]string{"false"},
+			},
+			&descriptorpb.FieldOptions{Deprecated: proto.Bool(false)},
+			nil,
+		},
+		{
+			"editions explicit presence",
+			args{
+				&gofeaturespb.GoFeatures{},
+				"legacyUnmarshalJsonEnum",
+				[]string{"false"},
+			},
+			&gofeaturespb.GoFeatures{LegacyUnmarshalJsonEnum: proto.Bool(false)},
+			nil,
+		},
+		{
+			"extension",
+			args{
+				&descriptorpb.FieldDescriptorProto{},
+				"options.[google.api.field_behavior]",
+				[]string{"REQUIRED", "IMMUTABLE"},
+			},
+			fieldBehavior(annotations.FieldBehavior_REQUIRED, annotations.FieldBehavior_IMMUTABLE),
+			nil,
+		},
+		{
+			"extension of another message",
+			args{
+				&descriptorpb.FieldOptions{},
+				"[google.api.http]",
+				[]string{"{}"},
+			},
+			nil,
+			runtime.ErrUnknownField,
+		},
+		{
+			"unclosed extension bracket",
+			args{
+				&descriptorpb.FieldOptions{},
+				"[google.api.field_behavior",
+				[]string{"REQUIRED"},
+			},
+			nil,
+			runtime.ErrInvalidFieldPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runtime.PopulateField(tt.args.m.ProtoReflect(), tt.args.fieldPath, tt.args.values...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PopulateField() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.want != nil && !proto.Equal(tt.args.m, tt.want) {
+				t.Errorf("PopulateField() got = %v, want %v", tt.args.m, tt.want)
+			}
+		})
+	}
+}
+
+func fieldBehavior(ff ...annotations.FieldBehavior) *descriptorpb.FieldDescriptorProto {
+	o := &descriptorpb.FieldOptions{}
+	proto.SetExtension(o, annotations.E_FieldBehavior, ff)
+
+	return &descriptorpb.FieldDescriptorProto{Options: o}
+}
+
+func TestPopulateQuery(t *testing.T) {
+	type args struct {
+		query url.Values
+		skip  []string
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    proto.Message
+		wantErr bool
+	}{
+		{
+			"fields",
+			args{
+				url.Values{
+					"selector":     {"example.Service.Method"},
+					"responseBody": {"data"},
+					"unknown":      {"value"},
+				},
+				nil,
+			},
+			&annotations.HttpRule{Selector: "example.Service.Method", ResponseBody: "data"},
+			false,
+		},
+		{
+			"skip",
+			args{
+				url.Values{
+					"selector":    {"example.Service.Method"},
+					"custom.kind": {"HEAD"},
+					"custom.path": {"/v1"},
+				},
+				[]string{"Selector", "custom"},
+			},
+			&annotations.HttpRule{},
+			false,
+		},
+		{
+			"skip all",
+			args{
+				url.Values{
+					"selector": {"example.Service.Method"},
+				},
+				[]string{"*"},
+			},
+			&annotations.HttpRule{},
+			false,
+		},
+		{
+			"invalid field path",
+			args{
+				url.Values{
+					"selector.value": {"example.Service.Method"},
+				},
+				nil,
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &annotations.HttpRule{}
+
+			err := runtime.PopulateQuery(got, tt.args.query, tt.args.skip...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PopulateQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.want != nil && !proto.Equal(got, tt.want) {
+				t.Errorf("PopulateQuery() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FieldMaskFromJSON returns paths of fields of message md that are present in JSON object data,
+// see https://google.aip.dev/134. Nested objects of message fields are traversed, repeated, map
+// and well-known type fields are leaves. JSON keys may be JSON or proto field names or extension
+// names in brackets, paths are composed of proto field names and sorted. Unknown keys are ignored.
+func FieldMaskFromJSON(md protoreflect.MessageDescriptor, data []byte) ([]string, error) {
+	var pp []string
+
+	if err := fieldMaskFromJSON(md, data, "", &pp); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(pp)
+
+	return pp, nil
+}
+
+func fieldMaskFromJSON(md protoreflect.MessageDescriptor, data []byte, prefix string, pp *[]string) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("parse JSON object of %s: %w", md.FullName(), err)
+	}
+
+	for k, v := range obj {
+		fd := findField(md, k)
+		if fd == nil {
+			continue
+		}
+
+		p := prefix + fieldName(fd)
+
+		if fd.Message() != nil && !fd.IsList() && !fd.IsMap() && !isWellKnownType(fd.Message()) && isJSONObject(v) {
+			n := len(*pp)
+
+			if err := fieldMaskFromJSON(fd.Message(), v, p+".", pp); err != nil {
+				return err
+			}
+
+			if len(*pp) > n {
+				continue
+			}
+
+			// empty object - the whole message is updated
+		}
+
+		*pp = append(*pp, p)
+	}
+
+	return nil
+}
+
+func isJSONObject(v json.RawMessage) bool {
+	v = bytes.TrimSpace(v)
+
+	return len(v) > 0 && v[0] == '{'
+}
+
+// isWellKnownType reports whether md is well-known type with special JSON mapping.
+func isWellKnownType(md protoreflect.MessageDescriptor) bool {
+	_, ok := _wellKnownTypes[md.FullName()]
+
+	return ok
+}
+
+var _wellKnownTypes = map[protoreflect.FullName]struct{}{
+	"google.protobuf.Any":         {},
+	"google.protobuf.Duration":    {},
+	"google.protobuf.Empty":       {},
+	"google.protobuf.FieldMask":   {},
+	"google.protobuf.ListValue":   {},
+	"google.protobuf.Struct":      {},
+	"google.protobuf.Timestamp":   {},
+	"google.protobuf.Value":       {},
+	"google.protobuf.BoolValue":   {},
+	"google.protobuf.BytesValue":  {},
+	"google.protobuf.DoubleValue": {},
+	"google.protobuf.FloatValue":  {},
+	"google.protobuf.Int32Value":  {},
+	"google.protobuf.Int64Value":  {},
+	"google.protobuf.StringValue": {},
+	"google.protobuf.UInt32Value": {},
+	"google.protobuf.UInt64Value": {},
+}
@@ -0,0 +1,112 @@
+package runtime_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/amsokol/protobuf-rest/runtime"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestFieldMaskFromJSON(t *testing.T) {
+	type args struct {
+		m    proto.Message
+		data string
+	}
+
+	tests := []struct {
+		name    string
+		args    args
+		want    []string
+		wantErr bool
+	}{
+		{
+			"scalar fields",
+			args{
+				&annotations.HttpRule{},
+				`{"selector": "example.Service.Method", "response_body": "", "unknown": 1}`,
+			},
+			[]string{"response_body", "selector"},
+			false,
+		},
+		{
+			"nested message",
+			args{
+				&annotations.HttpRule{},
+				`{"body": "*", "custom": {"kind": "HEAD", "path": null}}`,
+			},
+			[]string{"body", "custom.kind", "custom.path"},
+			false,
+		},
+		{
+			"empty and null nested message",
+			args{
+				&descriptorpb.FileDescriptorProto{},
+				`{"options": {}, "sourceCodeInfo": null}`,
+			},
+			[]string{"options", "source_code_info"},
+			false,
+		},
+		{
+			"nested message fields",
+			args{
+				&descriptorpb.FileDescriptorProto{},
+				`{"options": {"goPackage": "example", "uninterpretedOption": []}}`,
+			},
+			[]string{"options.go_package", "options.uninterpreted_option"},
+			false,
+		},
+		{
+			"repeated field",
+			args{
+				&annotations.HttpRule{},
+				`{"additionalBindings": [{"get": "/v1/items"}]}`,
+			},
+			[]string{"additional_bindings"},
+			false,
+		},
+		{
+			"well-known type",
+			args{
+				&errdetails.RetryInfo{},
+				`{"retryDelay": "1s"}`,
+			},
+			[]string{"retry_delay"},
+			false,
+		},
+		{
+			"extension",
+			args{
+				&descriptorpb.FieldDescriptorProto{},
+				`{"options": {"[google.api.field_behavior]": ["REQUIRED"]}}`,
+			},
+			[]string{"options.[google.api.field_behavior]"},
+			false,
+		},
+		{
+			"not object",
+			args{
+				&annotations.HttpRule{},
+				`["selector"]`,
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runtime.FieldMaskFromJSON(tt.args.m.ProtoReflect().Descriptor(), []byte(tt.args.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FieldMaskFromJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FieldMaskFromJSON() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
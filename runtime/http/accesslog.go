@@ -0,0 +1,201 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// RequestIDHeader is header of request ID. Request ID is generated if request does not have it
+// and is returned in the header of response.
+const RequestIDHeader = "X-Request-Id"
+
+// Redacted replaces values of fields marked with debug_redact option in access log.
+const Redacted = "[REDACTED]"
+
+// AccessLog configures access log of the Map.
+type AccessLog struct {
+	Handler slog.Handler // handler of access log entries
+	// Request makes entries include decoded request messages, values of fields with debug_redact option
+	// are replaced by Redacted.
+	Request bool
+}
+
+// UseAccessLog makes the Map write entry to access log for each request, including requests without matched route,
+// CORS preflight requests and gRPC-Web and Connect requests served by GRPCHandler.
+// Entries are written with message "access" and attributes:
+//
+//	method      HTTP method
+//	route       path template of the route, empty if there is no matched route, path values are not logged
+//	rpc         proto full method, e.g. "/helloworld.Greeter/SayHello", if the route serves RPC
+//	status      HTTP status code
+//	code        gRPC status code, e.g. "NotFound"
+//	latency     duration of request handling
+//	bytes_in    size of request body read by handler
+//	bytes_out   size of response body
+//	request_id  request ID, see RequestIDHeader
+//	request     decoded request message, see AccessLog.Request
+//
+// Responses with 5xx status code are logged with error level, other responses with info level.
+func (m *Map) UseAccessLog(l AccessLog) {
+	m.accessLog = &l
+}
+
+// handle calls handler h and writes access log entry of the request.
+func (l *AccessLog) handle(ctx context.Context, w http.ResponseWriter, r *http.Request, h Handler) {
+	start := time.Now()
+
+	id := r.Header.Get(RequestIDHeader)
+	if len(id) == 0 {
+		id = newRequestID()
+		// service implementation gets it in incoming metadata
+		r.Header.Set(RequestIDHeader, id)
+	}
+
+	w.Header().Set(RequestIDHeader, id)
+
+	ctx, rs := withRequestState(ctx)
+
+	body := &countingBody{ReadCloser: r.Body}
+	r = r.WithContext(ctx)
+	r.Body = body
+
+	rw := &recordingResponseWriter{ResponseWriter: w}
+
+	defer func() {
+		route := rs.route
+		code := rw.status()
+
+		var template string
+		if route != nil {
+			template = route.Path.String()
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("route", template),
+		}
+
+		if fm := route.FullMethod(); len(fm) > 0 {
+			attrs = append(attrs, slog.String("rpc", fm))
+		}
+
+		attrs = append(attrs, slog.Int("status", code))
+
+		if c, ok := rs.responseCode(code); ok {
+			attrs = append(attrs, slog.String("code", c.String()))
+		}
+
+		attrs = append(attrs,
+			slog.Duration("latency", time.Since(start)),
+			slog.Int64("bytes_in", body.read),
+			slog.Int64("bytes_out", rw.written),
+			slog.String("request_id", id),
+		)
+
+		if l.Request && rs.req != nil {
+			attrs = append(attrs, slog.Any("request", redact(rs.req.ProtoReflect())))
+		}
+
+		level := slog.LevelInfo
+		if code >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		// context of the request may be canceled
+		lctx := context.WithoutCancel(ctx)
+		if !l.Handler.Enabled(lctx, level) {
+			return
+		}
+
+		rec := slog.NewRecord(time.Now(), level, "access", 0)
+		rec.AddAttrs(attrs...)
+
+		_ = l.Handler.Handle(lctx, rec)
+	}()
+
+	h(ctx, rw, r)
+}
+
+func newRequestID() string {
+	var b [16]byte
+
+	_, _ = rand.Read(b[:])
+
+	return hex.EncodeToString(b[:])
+}
+
+// redact returns populated fields of message m by JSON names, values of fields with debug_redact option
+// are replaced by Redacted.
+func redact(m protoreflect.Message) map[string]interface{} {
+	v := make(map[string]interface{})
+
+	m.Range(func(fd protoreflect.FieldDescriptor, fv protoreflect.Value) bool {
+		name := fd.JSONName()
+		if fd.IsExtension() {
+			name = "[" + string(fd.FullName()) + "]"
+		}
+
+		if isRedacted(fd) {
+			v[name] = Redacted
+
+			return true
+		}
+
+		switch {
+		case fd.IsList():
+			l := fv.List()
+			vv := make([]interface{}, l.Len())
+
+			for i := range vv {
+				vv[i] = redactValue(fd, l.Get(i))
+			}
+
+			v[name] = vv
+		case fd.IsMap():
+			vv := make(map[string]interface{}, fv.Map().Len())
+
+			fv.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				vv[k.String()] = redactValue(fd.MapValue(), mv)
+
+				return true
+			})
+
+			v[name] = vv
+		default:
+			v[name] = redactValue(fd, fv)
+		}
+
+		return true
+	})
+
+	return v
+}
+
+// redactValue returns singular value of field fd.
+func redactValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return redact(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+
+		return int32(v.Enum())
+	default:
+		return v.Interface()
+	}
+}
+
+func isRedacted(fd protoreflect.FieldDescriptor) bool {
+	o, ok := fd.Options().(*descriptorpb.FieldOptions)
+
+	return ok && o.GetDebugRedact()
+}
@@ -0,0 +1,232 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// loginRequest returns descriptor of message with password field marked with debug_redact option.
+func loginRequest(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+
+	fd, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("example/login.proto"),
+		Package: proto.String("example"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("LoginRequest"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{
+					Name:     proto.String("user"),
+					JsonName: proto.String("user"),
+					Number:   proto.Int32(1),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				},
+				{
+					Name:     proto.String("password"),
+					JsonName: proto.String("password"),
+					Number:   proto.Int32(2),
+					Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+					Options:  &descriptorpb.FieldOptions{DebugRedact: proto.Bool(true)},
+				},
+			},
+		}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fd.Messages().Get(0)
+}
+
+func TestMap_UseAccessLog(t *testing.T) {
+	md := loginRequest(t)
+
+	tests := []struct {
+		name      string
+		target    string
+		header    http.Header
+		body      string
+		want      map[string]interface{}
+		wantLevel string
+	}{
+		{
+			"ok",
+			"/v1/users/alice:login",
+			http.Header{"X-Request-Id": {"42"}},
+			`{"password":"secret"}`,
+			map[string]interface{}{
+				"msg":        "access",
+				"method":     "POST",
+				"route":      "/v1/{user=users/*}:login",
+				"rpc":        "/example.Auth/Login",
+				"status":     float64(http.StatusOK),
+				"code":       "OK",
+				"bytes_in":   float64(21),
+				"bytes_out":  float64(2),
+				"request_id": "42",
+				"request":    map[string]interface{}{"user": "users/alice", "password": "[REDACTED]"},
+			},
+			"INFO",
+		},
+		{
+			"error",
+			"/v1/users/bob:login",
+			http.Header{"X-Request-Id": {"43"}},
+			`{"password":"fail"}`,
+			map[string]interface{}{
+				"msg":        "access",
+				"status":     float64(http.StatusInternalServerError),
+				"code":       "Internal",
+				"request_id": "43",
+				"request":    map[string]interface{}{"user": "users/bob", "password": "[REDACTED]"},
+			},
+			"ERROR",
+		},
+		{
+			"not found",
+			"/v1/unknown",
+			http.Header{"X-Request-Id": {"44"}},
+			"",
+			map[string]interface{}{
+				"msg":        "access",
+				"method":     "POST",
+				"route":      "",
+				"rpc":        nil,
+				"status":     float64(http.StatusNotFound),
+				"request_id": "44",
+			},
+			"INFO",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			m := _http.NewMap()
+			m.UseAccessLog(_http.AccessLog{Handler: slog.NewJSONHandler(&buf, nil), Request: true})
+
+			if err := m.Add("POST", "/v1/{user=users/*}:login", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				req := dynamicpb.NewMessage(md)
+				if err := _http.DecodeRequest(ctx, r, req, "*"); err != nil {
+					_http.WriteError(ctx, w, r, err)
+
+					return
+				}
+
+				if req.Get(md.Fields().ByName("password")).String() == "fail" {
+					_http.WriteError(ctx, w, r, status.Error(codes.Internal, "failed"))
+
+					return
+				}
+
+				_, _ = w.Write([]byte(`{}`))
+			}, _http.WithRPC("example.Auth", "Login")); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", tt.target, strings.NewReader(tt.body))
+			r.Header = tt.header
+
+			m.ServeHTTP(w, r)
+
+			if id := w.Header().Get("X-Request-Id"); id != tt.header.Get("X-Request-Id") {
+				t.Errorf("ServeHTTP() X-Request-Id = %v, want %v", id, tt.header.Get("X-Request-Id"))
+			}
+
+			if strings.Contains(buf.String(), "secret") || strings.Contains(buf.String(), tt.target) {
+				t.Errorf("UseAccessLog() leaks request data: %s", buf.String())
+			}
+
+			var got map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if got["level"] != tt.wantLevel {
+				t.Errorf("UseAccessLog() level = %v, want %v", got["level"], tt.wantLevel)
+			}
+
+			if _, ok := got["latency"]; !ok {
+				t.Error("UseAccessLog() latency is not logged")
+			}
+
+			for k, v := range tt.want {
+				if !reflect.DeepEqual(got[k], v) {
+					t.Errorf("UseAccessLog() %s = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestMap_UseAccessLog_requestID(t *testing.T) {
+	var buf bytes.Buffer
+
+	m := _http.NewMap()
+	m.UseAccessLog(_http.AccessLog{Handler: slog.NewJSONHandler(&buf, nil)})
+
+	var incoming string
+
+	if err := m.Add("GET", "/v1/ping", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		incoming = r.Header.Get("X-Request-Id")
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/v1/ping", nil))
+
+	id := w.Header().Get("X-Request-Id")
+	if len(id) != 32 || incoming != id {
+		t.Errorf("ServeHTTP() X-Request-Id = %q, request header %q, want generated ID", id, incoming)
+	}
+
+	if !strings.Contains(buf.String(), `"request_id":"`+id+`"`) {
+		t.Errorf("UseAccessLog() = %s, want request_id %s", buf.String(), id)
+	}
+}
+
+func TestMap_UseAccessLog_preflight(t *testing.T) {
+	var buf bytes.Buffer
+
+	m := _http.NewMap()
+	m.UseAccessLog(_http.AccessLog{Handler: slog.NewJSONHandler(&buf, nil)})
+	m.UseCORS(_http.CORS{AllowedOrigins: []string{"https://example.com"}})
+
+	if err := m.Add("GET", "/v1/ping", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest("OPTIONS", "/v1/ping", nil)
+	r.Header.Set("Origin", "https://example.com")
+	r.Header.Set("Access-Control-Request-Method", "GET")
+
+	m.ServeHTTP(httptest.NewRecorder(), r)
+
+	for _, s := range []string{`"method":"OPTIONS"`, `"route":""`} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("UseAccessLog() = %s, want %s in it", buf.String(), s)
+		}
+	}
+}
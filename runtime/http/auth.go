@@ -0,0 +1,201 @@
+package http
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Authentication schemes of built-in authenticators.
+const (
+	SchemeBearer = "bearer"
+	SchemeAPIKey = "api_key"
+	SchemeMTLS   = "mtls"
+)
+
+// Principal is authenticated client of the request.
+type Principal struct {
+	Subject string                 // identity of the client, e.g. user ID, API key ID or certificate subject
+	Scheme  string                 // authentication scheme, e.g. SchemeBearer
+	Scopes  []string               // scopes granted to the client
+	Claims  map[string]interface{} // other attributes of the credentials, optional
+}
+
+type principalKey struct{}
+
+// NewPrincipalContext returns new context that carries principal p. gRPC interceptors that authenticate
+// native gRPC requests may use it, so service implementations read principal the same way for REST and gRPC.
+func NewPrincipalContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns authenticated principal of the request, nil if the request is not authenticated.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+
+	return p
+}
+
+// Authenticator authenticates HTTP requests. The context has matched route, so authenticator
+// knows RPC of the request, see RouteFromContext.
+type Authenticator interface {
+	// Authenticate returns principal of the request, nil if the request has no credentials
+	// of the authenticator. Error rejects the request, errors without gRPC status are UNAUTHENTICATED.
+	Authenticate(ctx context.Context, r *http.Request) (*Principal, error)
+}
+
+// AuthenticatorFunc is function that implements Authenticator.
+type AuthenticatorFunc func(ctx context.Context, r *http.Request) (*Principal, error)
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, r *http.Request) (*Principal, error) {
+	return f(ctx, r)
+}
+
+// UseAuthenticator adds authenticators of all routes. Authenticators are called in order they are added
+// after the route is matched and before the handler decodes request, principal of the first authenticator that
+// recognizes credentials of the request is set to the context, see PrincipalFromContext.
+// Requests without credentials are passed to the handler unauthenticated.
+func (m *Map) UseAuthenticator(aa ...Authenticator) {
+	m.authenticators = append(m.authenticators, aa...)
+}
+
+// authenticateHandler returns handler that authenticates request with authenticators of the Map
+// and checks authorization policy of the route before h. Rejected requests are rate limited.
+func (m *Map) authenticateHandler(route *Route, h Handler) Handler {
+	if len(m.authenticators) == 0 && len(route.policy.Scopes) == 0 {
+		return h
+	}
+
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		// rejected requests are rate limited as anonymous, see UseRateLimiting
+		reject := func(err error) {
+			if m.allow(ctx, w, r, route) {
+				WriteError(ctx, w, r, err)
+			}
+		}
+
+		for _, a := range m.authenticators {
+			p, err := a.Authenticate(ctx, r)
+			if err != nil {
+				if _, ok := status.FromError(err); !ok {
+					err = status.Error(codes.Unauthenticated, err.Error())
+				}
+
+				reject(err)
+
+				return
+			}
+
+			if p != nil {
+				ctx = NewPrincipalContext(ctx, p)
+				r = r.WithContext(ctx)
+
+				break
+			}
+		}
+
+		if err := route.policy.Check(ctx, route.FullMethod()); err != nil {
+			reject(err)
+
+			return
+		}
+
+		h(ctx, w, r)
+	}
+}
+
+// errInvalidCredentials is returned by built-in authenticators if verifier does not return principal.
+var errInvalidCredentials = status.Error(codes.Unauthenticated, "invalid credentials")
+
+// BearerAuthenticator authenticates requests with "Authorization: Bearer <token>" header.
+type BearerAuthenticator struct {
+	// Verify returns principal of valid token, e.g. of verified JWT, or error.
+	// Scheme of the principal is SchemeBearer if empty.
+	Verify func(ctx context.Context, token string) (*Principal, error)
+}
+
+func (a *BearerAuthenticator) Authenticate(ctx context.Context, r *http.Request) (*Principal, error) {
+	h := r.Header.Get("Authorization")
+
+	scheme, token, ok := strings.Cut(h, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, nil
+	}
+
+	p, err := a.Verify(ctx, strings.TrimSpace(token))
+
+	return verified(SchemeBearer, p, err)
+}
+
+// APIKeyAuthenticator authenticates requests with API key in request header or query parameter.
+type APIKeyAuthenticator struct {
+	Header string // header of API key, e.g. "X-Api-Key", optional
+	Query  string // query parameter of API key, e.g. "key", optional
+	// Verify returns principal of valid API key or error. Scheme of the principal is SchemeAPIKey if empty.
+	Verify func(ctx context.Context, key string) (*Principal, error)
+}
+
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, r *http.Request) (*Principal, error) {
+	var key string
+
+	if len(a.Header) > 0 {
+		key = r.Header.Get(a.Header)
+	}
+
+	if len(key) == 0 && len(a.Query) > 0 {
+		key = r.URL.Query().Get(a.Query)
+	}
+
+	if len(key) == 0 {
+		return nil, nil
+	}
+
+	p, err := a.Verify(ctx, key)
+
+	return verified(SchemeAPIKey, p, err)
+}
+
+// ClientCertAuthenticator authenticates requests with client TLS certificate verified by the server,
+// i.e. http.Server with tls.Config.ClientAuth that verifies certificates.
+type ClientCertAuthenticator struct {
+	// Verify returns principal of the certificate or error, optional. By default principal has
+	// common name of the certificate subject and SchemeMTLS.
+	Verify func(ctx context.Context, cert *x509.Certificate) (*Principal, error)
+}
+
+func (a *ClientCertAuthenticator) Authenticate(ctx context.Context, r *http.Request) (*Principal, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, nil
+	}
+
+	cert := r.TLS.VerifiedChains[0][0]
+
+	if a.Verify == nil {
+		return &Principal{Subject: cert.Subject.CommonName, Scheme: SchemeMTLS}, nil
+	}
+
+	p, err := a.Verify(ctx, cert)
+
+	return verified(SchemeMTLS, p, err)
+}
+
+// verified returns principal returned by verifier with default scheme, error if there is no principal.
+func verified(scheme string, p *Principal, err error) (*Principal, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	if p == nil {
+		return nil, errInvalidCredentials
+	}
+
+	if len(p.Scheme) == 0 {
+		p.Scheme = scheme
+	}
+
+	return p, nil
+}
@@ -0,0 +1,127 @@
+package http_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMap_UseAuthenticator(t *testing.T) {
+	verify := func(want string) func(ctx context.Context, s string) (*_http.Principal, error) {
+		return func(ctx context.Context, s string) (*_http.Principal, error) {
+			switch s {
+			case want:
+				return &_http.Principal{Subject: "alice"}, nil
+			case "revoked":
+				return nil, status.Error(codes.PermissionDenied, "revoked")
+			case "broken":
+				return nil, errors.New("broken")
+			default:
+				return nil, nil
+			}
+		}
+	}
+
+	client := &x509.Certificate{Subject: pkix.Name{CommonName: "client"}}
+
+	tests := []struct {
+		name        string
+		target      string
+		header      http.Header
+		tls         *tls.ConnectionState
+		wantCode    int
+		wantSubject string
+		wantScheme  string
+	}{
+		{"bearer", "/v1/me", http.Header{"Authorization": {"Bearer token"}}, nil, http.StatusOK, "alice", _http.SchemeBearer},
+		{"bearer invalid", "/v1/me", http.Header{"Authorization": {"bearer other"}}, nil, http.StatusUnauthorized, "", ""},
+		{"bearer error", "/v1/me", http.Header{"Authorization": {"Bearer broken"}}, nil, http.StatusUnauthorized, "", ""},
+		{"bearer status", "/v1/me", http.Header{"Authorization": {"Bearer revoked"}}, nil, http.StatusForbidden, "", ""},
+		{"API key header", "/v1/me", http.Header{"X-Api-Key": {"key"}}, nil, http.StatusOK, "alice", _http.SchemeAPIKey},
+		{"API key query", "/v1/me?key=key", http.Header{}, nil, http.StatusOK, "alice", _http.SchemeAPIKey},
+		{"API key invalid", "/v1/me?key=other", http.Header{}, nil, http.StatusUnauthorized, "", ""},
+		{
+			"client certificate",
+			"/v1/me",
+			http.Header{},
+			&tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{client}}},
+			http.StatusOK,
+			"client",
+			_http.SchemeMTLS,
+		},
+		{
+			"unverified client certificate",
+			"/v1/me",
+			http.Header{},
+			&tls.ConnectionState{PeerCertificates: []*x509.Certificate{client}},
+			http.StatusOK,
+			"",
+			"",
+		},
+		{
+			"first authenticator",
+			"/v1/me?key=key",
+			http.Header{"Authorization": {"Bearer token"}},
+			nil,
+			http.StatusOK,
+			"alice",
+			_http.SchemeBearer,
+		},
+		{"anonymous", "/v1/me", http.Header{}, nil, http.StatusOK, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *_http.Principal
+
+			m := _http.NewMap()
+			m.UseAuthenticator(
+				&_http.BearerAuthenticator{Verify: verify("token")},
+				&_http.APIKeyAuthenticator{Header: "X-Api-Key", Query: "key", Verify: verify("key")},
+				&_http.ClientCertAuthenticator{},
+			)
+
+			if err := m.Add("GET", "/v1/me", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				got = _http.PrincipalFromContext(ctx)
+
+				if _http.PrincipalFromContext(r.Context()) != got {
+					t.Error("PrincipalFromContext() of request context differs")
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", tt.target, nil)
+			r.Header = tt.header
+			r.TLS = tt.tls
+
+			m.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("ServeHTTP() code = %v, want %v: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+
+			if len(tt.wantSubject) == 0 {
+				if got != nil {
+					t.Errorf("PrincipalFromContext() = %+v, want nil", got)
+				}
+
+				return
+			}
+
+			if got == nil || got.Subject != tt.wantSubject || got.Scheme != tt.wantScheme {
+				t.Errorf("PrincipalFromContext() = %+v, want %s of %s", got, tt.wantSubject, tt.wantScheme)
+			}
+		})
+	}
+}
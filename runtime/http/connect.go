@@ -0,0 +1,356 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	// ConnectProtoContentType is content type of Connect unary requests with binary messages.
+	ConnectProtoContentType = "application/proto"
+
+	// ConnectJSONContentType is content type of Connect unary requests with JSON messages.
+	ConnectJSONContentType = "application/json"
+
+	// ConnectProtocolVersionHeader is header that distinguishes Connect unary JSON requests from REST requests.
+	ConnectProtocolVersionHeader = "Connect-Protocol-Version"
+
+	// ConnectTimeoutHeader is header of Connect request timeout in milliseconds.
+	ConnectTimeoutHeader = "Connect-Timeout-Ms"
+)
+
+// isConnectRequest reports whether r is Connect unary request of one of methods of gRPC server,
+// i.e. POST request of "application/proto" content type or "application/json" content type
+// with ConnectProtocolVersionHeader.
+func isConnectRequest(methods map[string]bool, r *http.Request) bool {
+	if r.Method != http.MethodPost || !methods[r.URL.Path] {
+		return false
+	}
+
+	switch mediaType(r) {
+	case ConnectProtoContentType:
+		return true
+	case ConnectJSONContentType:
+		return len(r.Header.Get(ConnectProtocolVersionHeader)) > 0
+	default:
+		return false
+	}
+}
+
+// serveConnect serves Connect unary request with gRPC server gs. Request message is sent
+// to gRPC server as native gRPC request, response message or error is written as Connect response.
+// JSON messages are converted with message types of protoregistry.GlobalTypes. Request body is limited by l.
+func serveConnect(gs http.Handler, w http.ResponseWriter, r *http.Request, l Limits) {
+	t := mediaType(r)
+	isJSON := t == ConnectJSONContentType
+
+	if e := r.Header.Get("Content-Encoding"); len(e) > 0 && e != "identity" {
+		writeConnectError(w, nil, &spb.Status{
+			Code:    int32(codes.Unimplemented),
+			Message: fmt.Sprintf("unsupported content encoding '%s'", e),
+		})
+
+		return
+	}
+
+	in, out, err := connectMessages(r.URL.Path)
+	if err != nil && isJSON {
+		writeConnectError(w, nil, &spb.Status{Code: int32(codes.Unimplemented), Message: err.Error()})
+
+		return
+	}
+
+	data, err := l.readBody(r)
+	if err != nil {
+		writeConnectError(w, nil, status.Convert(err).Proto())
+
+		return
+	}
+
+	if isJSON {
+		if data, err = jsonToProto(in, data); err != nil {
+			writeConnectError(w, nil, &spb.Status{Code: int32(codes.InvalidArgument), Message: err.Error()})
+
+			return
+		}
+	}
+
+	frame := make([]byte, 5, 5+len(data))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	frame = append(frame, data...)
+
+	req := grpcRequest(r, GRPCContentType+"+proto", bytes.NewReader(frame))
+
+	if v := r.Header.Get(ConnectTimeoutHeader); len(v) > 0 {
+		ms, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || len(v) > 10 {
+			writeConnectError(w, nil, &spb.Status{
+				Code:    int32(codes.InvalidArgument),
+				Message: fmt.Sprintf("invalid %s '%s'", ConnectTimeoutHeader, v),
+			})
+
+			return
+		}
+
+		req.Header.Set(GRPCTimeoutHeader, grpcTimeout(ms))
+	}
+
+	rec := &connectResponseWriter{header: make(http.Header)}
+
+	gs.ServeHTTP(rec, req)
+
+	if s := rec.status(); s.GetCode() != int32(codes.OK) {
+		writeConnectError(w, rec.header, s)
+
+		return
+	}
+
+	data, err = rec.message()
+	if err == nil && isJSON {
+		data, err = protoToJSON(out, data)
+	}
+
+	if err != nil {
+		writeConnectError(w, rec.header, &spb.Status{Code: int32(codes.Internal), Message: err.Error()})
+
+		return
+	}
+
+	writeConnectMetadata(w, rec.header)
+	w.Header().Set("Content-Type", t)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// connectMessages returns request and response message types of gRPC method by path "/<package>.<Service>/<Method>".
+func connectMessages(path string) (protoreflect.MessageType, protoreflect.MessageType, error) {
+	i := strings.LastIndexByte(path, '/')
+
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(path[1:i]))
+	if err != nil {
+		return nil, nil, fmt.Errorf("find service of '%s': %w", path, err)
+	}
+
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, nil, fmt.Errorf("'%s' is not service", d.FullName())
+	}
+
+	md := sd.Methods().ByName(protoreflect.Name(path[i+1:]))
+	if md == nil {
+		return nil, nil, fmt.Errorf("unknown method '%s'", path)
+	}
+
+	in, err := protoregistry.GlobalTypes.FindMessageByName(md.Input().FullName())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, err := protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return in, out, nil
+}
+
+func jsonToProto(mt protoreflect.MessageType, data []byte) ([]byte, error) {
+	m := mt.New().Interface()
+
+	if len(data) > 0 {
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, m); err != nil {
+			return nil, fmt.Errorf("decode request message: %w", err)
+		}
+	}
+
+	return proto.Marshal(m)
+}
+
+func protoToJSON(mt protoreflect.MessageType, data []byte) ([]byte, error) {
+	m := mt.New().Interface()
+
+	if err := proto.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("decode response message: %w", err)
+	}
+
+	return protojson.Marshal(m)
+}
+
+// grpcTimeout returns value of Grpc-Timeout header of ms milliseconds, gRPC timeout has up to 8 digits.
+func grpcTimeout(ms uint64) string {
+	if ms < 1e8 {
+		return strconv.FormatUint(ms, 10) + "m"
+	}
+
+	return strconv.FormatUint(ms/1000, 10) + "S"
+}
+
+// connectResponseWriter buffers response of gRPC server to unary request.
+type connectResponseWriter struct {
+	header http.Header // headers and trailers set by gRPC server
+	code   int
+	body   bytes.Buffer
+}
+
+func (rw *connectResponseWriter) Header() http.Header {
+	return rw.header
+}
+
+func (rw *connectResponseWriter) WriteHeader(code int) {
+	if rw.code == 0 {
+		rw.code = code
+	}
+}
+
+func (rw *connectResponseWriter) Write(p []byte) (int, error) {
+	rw.WriteHeader(http.StatusOK)
+
+	return rw.body.Write(p)
+}
+
+func (rw *connectResponseWriter) Flush() {}
+
+// status returns status of gRPC call from trailers set by gRPC server.
+func (rw *connectResponseWriter) status() *spb.Status {
+	if v := rw.header.Get("Grpc-Status-Details-Bin"); len(v) > 0 {
+		if data, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(v, "=")); err == nil {
+			s := &spb.Status{}
+			if err := proto.Unmarshal(data, s); err == nil {
+				return s
+			}
+		}
+	}
+
+	v := rw.header.Get("Grpc-Status")
+	if len(v) == 0 {
+		// request is rejected by gRPC server before the call, e.g. 400 Bad Request
+		return &spb.Status{Code: int32(codes.Internal), Message: strings.TrimSpace(rw.body.String())}
+	}
+
+	code, err := strconv.Atoi(v)
+	if err != nil {
+		return &spb.Status{Code: int32(codes.Internal), Message: fmt.Sprintf("invalid grpc-status '%s'", v)}
+	}
+
+	msg := rw.header.Get("Grpc-Message")
+	if m, err := url.PathUnescape(msg); err == nil {
+		msg = m
+	}
+
+	return &spb.Status{Code: int32(code), Message: msg}
+}
+
+// message returns response message from the gRPC frame of response body.
+func (rw *connectResponseWriter) message() ([]byte, error) {
+	data := rw.body.Bytes()
+
+	if len(data) < 5 {
+		return nil, fmt.Errorf("gRPC response has no message")
+	}
+
+	if data[0] != 0 {
+		return nil, fmt.Errorf("compressed gRPC response is not supported")
+	}
+
+	n := binary.BigEndian.Uint32(data[1:5])
+	if uint64(len(data)-5) < uint64(n) {
+		return nil, fmt.Errorf("gRPC response message is truncated")
+	}
+
+	return data[5 : 5+n], nil
+}
+
+// writeConnectMetadata sets custom headers of gRPC response as response headers
+// and trailers as response headers with "Trailer-" prefix.
+func writeConnectMetadata(w http.ResponseWriter, h http.Header) {
+	for k, vv := range h {
+		switch {
+		case k == "Trailer" || k == "Content-Type" || strings.HasPrefix(k, "Grpc-"):
+		case strings.HasPrefix(k, http.TrailerPrefix):
+			for _, v := range vv {
+				w.Header().Add("Trailer-"+strings.TrimPrefix(k, http.TrailerPrefix), v)
+			}
+		default:
+			w.Header()[k] = vv
+		}
+	}
+}
+
+// connectError is JSON body of Connect error response.
+type connectError struct {
+	Code    string               `json:"code"`
+	Message string               `json:"message,omitempty"`
+	Details []connectErrorDetail `json:"details,omitempty"`
+}
+
+type connectErrorDetail struct {
+	Type  string `json:"type"`  // proto full name of detail message
+	Value string `json:"value"` // base64 encoded detail message
+}
+
+// writeConnectError writes gRPC status s as Connect error with HTTP status code converted from gRPC code.
+func writeConnectError(w http.ResponseWriter, h http.Header, s *spb.Status) {
+	e := connectError{
+		Code:    connectCode(codes.Code(s.GetCode())),
+		Message: s.GetMessage(),
+	}
+
+	for _, d := range s.GetDetails() {
+		e.Details = append(e.Details, connectErrorDetail{
+			Type:  string(d.MessageName()),
+			Value: base64.RawStdEncoding.EncodeToString(d.GetValue()),
+		})
+	}
+
+	data, _ := json.Marshal(e)
+
+	writeConnectMetadata(w, h)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(HTTPStatusFromCode(codes.Code(s.GetCode())))
+	_, _ = w.Write(data)
+}
+
+// connectCodes are Connect codes of gRPC codes, see https://connectrpc.com/docs/protocol#error-codes.
+var connectCodes = map[codes.Code]string{
+	codes.Canceled:           "canceled",
+	codes.Unknown:            "unknown",
+	codes.InvalidArgument:    "invalid_argument",
+	codes.DeadlineExceeded:   "deadline_exceeded",
+	codes.NotFound:           "not_found",
+	codes.AlreadyExists:      "already_exists",
+	codes.PermissionDenied:   "permission_denied",
+	codes.ResourceExhausted:  "resource_exhausted",
+	codes.FailedPrecondition: "failed_precondition",
+	codes.Aborted:            "aborted",
+	codes.OutOfRange:         "out_of_range",
+	codes.Unimplemented:      "unimplemented",
+	codes.Internal:           "internal",
+	codes.Unavailable:        "unavailable",
+	codes.DataLoss:           "data_loss",
+	codes.Unauthenticated:    "unauthenticated",
+}
+
+// connectCode returns Connect code of gRPC code, e.g. "not_found" of NotFound.
+// Codes that are not defined by Connect are "unknown".
+func connectCode(c codes.Code) string {
+	if s, ok := connectCodes[c]; ok {
+		return s
+	}
+
+	return "unknown"
+}
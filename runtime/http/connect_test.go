@@ -0,0 +1,236 @@
+package http_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func (*healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	switch req.GetService() {
+	case "":
+		_ = grpc.SetHeader(ctx, metadata.Pairs("x-served-by", "grpc"))
+		_ = grpc.SetTrailer(ctx, metadata.Pairs("x-trailer", "done"))
+
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+	case "deadline":
+		if _, ok := ctx.Deadline(); !ok {
+			return nil, status.Error(codes.FailedPrecondition, "no deadline")
+		}
+
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING}, nil
+	case "exhausted":
+		return nil, status.Error(codes.ResourceExhausted, "quota exceeded")
+	case "custom":
+		return nil, status.Error(codes.Code(17), "custom code")
+	default:
+		s, _ := status.New(codes.NotFound, "unknown service").WithDetails(&errdetails.ErrorInfo{Reason: "UNKNOWN"})
+
+		return nil, s.Err()
+	}
+}
+
+func TestGRPCHandler_Connect(t *testing.T) {
+	gs := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(gs, &healthServer{})
+
+	m := _http.NewMap()
+
+	if err := m.Add("POST", "/grpc.health.v1.Health/Check", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`"REST"`))
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	h := _http.GRPCHandler(gs, m)
+
+	serving, err := proto.Marshal(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := proto.Marshal(&errdetails.ErrorInfo{Reason: "UNKNOWN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		header     http.Header
+		body       string
+		wantCode   int
+		wantHeader http.Header
+		want       string
+	}{
+		{
+			"JSON",
+			http.Header{"Content-Type": {"application/json"}, "Connect-Protocol-Version": {"1"}},
+			`{}`,
+			http.StatusOK,
+			http.Header{"Content-Type": {"application/json"}, "X-Served-By": {"grpc"}, "Trailer-X-Trailer": {"done"}},
+			`{"status":"SERVING"}`,
+		},
+		{
+			"proto",
+			http.Header{"Content-Type": {"application/proto"}},
+			"",
+			http.StatusOK,
+			http.Header{"Content-Type": {"application/proto"}, "X-Served-By": {"grpc"}, "Trailer-X-Trailer": {"done"}},
+			string(serving),
+		},
+		{
+			"timeout",
+			http.Header{"Content-Type": {"application/json"}, "Connect-Protocol-Version": {"1"}, "Connect-Timeout-Ms": {"10000"}},
+			`{"service":"deadline"}`,
+			http.StatusOK,
+			http.Header{"Content-Type": {"application/json"}},
+			`{"status":"NOT_SERVING"}`,
+		},
+		{
+			"error",
+			http.Header{"Content-Type": {"application/json"}, "Connect-Protocol-Version": {"1"}},
+			`{"service":"unknown"}`,
+			http.StatusNotFound,
+			http.Header{"Content-Type": {"application/json"}},
+			`{"code":"not_found","message":"unknown service","details":[{"type":"google.rpc.ErrorInfo","value":"` +
+				base64.RawStdEncoding.EncodeToString(info) + `"}]}`,
+		},
+		{
+			"error code",
+			http.Header{"Content-Type": {"application/json"}, "Connect-Protocol-Version": {"1"}},
+			`{"service":"exhausted"}`,
+			http.StatusTooManyRequests,
+			http.Header{"Content-Type": {"application/json"}},
+			`{"code":"resource_exhausted","message":"quota exceeded"}`,
+		},
+		{
+			"unknown error code",
+			http.Header{"Content-Type": {"application/json"}, "Connect-Protocol-Version": {"1"}},
+			`{"service":"custom"}`,
+			http.StatusInternalServerError,
+			http.Header{"Content-Type": {"application/json"}},
+			`{"code":"unknown","message":"custom code"}`,
+		},
+		{
+			"invalid JSON",
+			http.Header{"Content-Type": {"application/json"}, "Connect-Protocol-Version": {"1"}},
+			`{"service":1}`,
+			http.StatusBadRequest,
+			http.Header{"Content-Type": {"application/json"}},
+			"",
+		},
+		{
+			"REST",
+			http.Header{"Content-Type": {"application/json"}},
+			`{}`,
+			http.StatusOK,
+			http.Header{},
+			`"REST"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/grpc.health.v1.Health/Check", strings.NewReader(tt.body))
+			r.Header = tt.header
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("ServeHTTP() code = %v, want %v: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+
+			for k := range tt.wantHeader {
+				if got := w.Header().Get(k); got != tt.wantHeader.Get(k) {
+					t.Errorf("ServeHTTP() header %s = %v, want %v", k, got, tt.wantHeader.Get(k))
+				}
+			}
+
+			if len(tt.want) == 0 {
+				return
+			}
+
+			if tt.header.Get("Content-Type") == "application/proto" {
+				if w.Body.String() != tt.want {
+					t.Errorf("ServeHTTP() = %q, want %q", w.Body.String(), tt.want)
+				}
+
+				return
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ServeHTTP() = %s, want %s", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGRPCHandler_ConnectLimits(t *testing.T) {
+	gs := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(gs, &healthServer{})
+
+	tests := []struct {
+		name     string
+		route    []_http.RouteOption // options of REST route of the method, nil if there is no route
+		wantCode int
+	}{
+		{"map limit", nil, http.StatusTooManyRequests},
+		{
+			"route limit",
+			[]_http.RouteOption{_http.WithRPC("grpc.health.v1.Health", "Check"), _http.WithLimits(_http.Limits{MaxBodySize: 1024})},
+			http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := _http.NewMap()
+			m.UseLimits(_http.Limits{MaxBodySize: 8})
+
+			if tt.route != nil {
+				if err := m.Add("POST", "/v1/health:check", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {}, tt.route...); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/grpc.health.v1.Health/Check", strings.NewReader(`{"service":""}`))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("Connect-Protocol-Version", "1")
+
+			_http.GRPCHandler(gs, m).ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("ServeHTTP() code = %v, want %v: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package http
+
+import (
+	"context"
+
+	"github.com/amsokol/protobuf-rest/runtime"
+)
+
+type (
+	routeKey  struct{}
+	valuesKey struct{}
+)
+
+// NewContext returns new context that carries matched route and path values.
+func NewContext(ctx context.Context, r *Route, v runtime.Values) context.Context {
+	ctx = context.WithValue(ctx, routeKey{}, r)
+
+	return context.WithValue(ctx, valuesKey{}, v)
+}
+
+// RouteFromContext returns route matched for the request, nil if there is no route.
+func RouteFromContext(ctx context.Context) *Route {
+	r, _ := ctx.Value(routeKey{}).(*Route)
+
+	return r
+}
+
+// ValuesFromContext returns path values of the request, nil if there are no values.
+func ValuesFromContext(ctx context.Context) runtime.Values {
+	v, _ := ctx.Value(valuesKey{}).(runtime.Values)
+
+	return v
+}
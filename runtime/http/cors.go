@@ -0,0 +1,285 @@
+package http
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amsokol/protobuf-rest/runtime"
+)
+
+// CORS is Cross-Origin Resource Sharing policy of the Map,
+// see https://developer.mozilla.org/en-US/docs/Web/HTTP/CORS.
+type CORS struct {
+	// AllowedOrigins are exact origins, e.g. "https://example.com", origins with one wildcard,
+	// e.g. "https://*.example.com", or "*" for any origin. Origins are compared case-insensitively.
+	AllowedOrigins []string
+	// AllowedOriginPatterns are regular expressions of allowed origins, e.g. `^https://[a-z]+\.example\.com$`.
+	AllowedOriginPatterns []*regexp.Regexp
+	// AllowedMethods limit methods allowed by preflight requests.
+	// By default methods of routes registered for the request path are allowed.
+	AllowedMethods []string
+	// AllowedHeaders are request headers allowed by preflight requests, "*" allows any headers.
+	// Default is DefaultCORSAllowedHeaders.
+	AllowedHeaders []string
+	// ExposedHeaders are response headers available to browser scripts.
+	ExposedHeaders []string
+	// AllowCredentials allows requests with cookies and HTTP authentication.
+	AllowCredentials bool
+	// MaxAge is how long results of preflight requests may be cached, it is not sent if zero.
+	MaxAge time.Duration
+}
+
+// DefaultCORSAllowedHeaders are request headers allowed if CORS.AllowedHeaders is empty.
+var DefaultCORSAllowedHeaders = []string{"Accept", "Content-Type", "X-Requested-With"}
+
+// UseCORS sets CORS policy of the Map. Preflight requests are answered by the Map
+// with methods of routes that match the request path, other requests of allowed origins
+// get CORS headers before the route handler is called.
+func (m *Map) UseCORS(c CORS) {
+	p := &corsPolicy{
+		CORS:           c,
+		allowedMethods: make(map[string]struct{}, len(c.AllowedMethods)),
+		allowedHeaders: make(map[string]struct{}, len(c.AllowedHeaders)),
+	}
+
+	for _, o := range c.AllowedOrigins {
+		o = strings.ToLower(o)
+
+		if o == "*" {
+			p.anyOrigin = true
+		} else if i := strings.IndexByte(o, '*'); i >= 0 {
+			p.wildcards = append(p.wildcards, [2]string{o[:i], o[i+1:]})
+		} else {
+			p.origins = append(p.origins, o)
+		}
+	}
+
+	for _, method := range c.AllowedMethods {
+		p.allowedMethods[strings.ToUpper(method)] = struct{}{}
+	}
+
+	hh := c.AllowedHeaders
+	if len(hh) == 0 {
+		hh = DefaultCORSAllowedHeaders
+	}
+
+	for _, h := range hh {
+		if h == "*" {
+			p.anyHeader = true
+		}
+
+		p.allowedHeaders[http.CanonicalHeaderKey(h)] = struct{}{}
+	}
+
+	m.cors = p
+}
+
+// corsPolicy is CORS policy prepared for matching.
+type corsPolicy struct {
+	CORS
+
+	anyOrigin      bool
+	origins        []string    // lower-cased exact origins
+	wildcards      [][2]string // prefixes and suffixes of wildcard origins
+	allowedMethods map[string]struct{}
+	anyHeader      bool
+	allowedHeaders map[string]struct{} // canonical header keys
+}
+
+// isPreflight reports whether r is CORS preflight request.
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions &&
+		len(r.Header.Get("Origin")) > 0 &&
+		len(r.Header.Get("Access-Control-Request-Method")) > 0
+}
+
+// allowOrigin returns value of Access-Control-Allow-Origin header for origin, empty if it is not allowed.
+func (p *corsPolicy) allowOrigin(origin string) string {
+	if p.anyOrigin {
+		if p.AllowCredentials {
+			// wildcard is not allowed with credentials
+			return origin
+		}
+
+		return "*"
+	}
+
+	o := strings.ToLower(origin)
+
+	for _, s := range p.origins {
+		if o == s {
+			return origin
+		}
+	}
+
+	for _, w := range p.wildcards {
+		if len(o) >= len(w[0])+len(w[1]) && strings.HasPrefix(o, w[0]) && strings.HasSuffix(o, w[1]) {
+			return origin
+		}
+	}
+
+	for _, re := range p.AllowedOriginPatterns {
+		if re.MatchString(origin) {
+			return origin
+		}
+	}
+
+	return ""
+}
+
+// setOrigin sets Access-Control-Allow-Origin and Vary headers, it returns false if origin is not allowed.
+func (p *corsPolicy) setOrigin(h http.Header, origin string) bool {
+	allow := p.allowOrigin(origin)
+
+	if allow != "*" {
+		// response depends on origin
+		h.Add("Vary", "Origin")
+	}
+
+	if len(allow) == 0 {
+		return false
+	}
+
+	h.Set("Access-Control-Allow-Origin", allow)
+
+	if p.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	return true
+}
+
+// rpcRequestHeaders are request headers of gRPC-Web and Connect protocols
+// that preflight requests of gRPC methods allow in addition to allowed headers.
+var rpcRequestHeaders = map[string]struct{}{
+	"Content-Type":               {},
+	"X-Grpc-Web":                 {},
+	"X-User-Agent":               {},
+	GRPCTimeoutHeader:            {},
+	ConnectProtocolVersionHeader: {},
+	ConnectTimeoutHeader:         {},
+}
+
+// rpcExposedHeaders are response headers of gRPC-Web protocol exposed to browser scripts in addition to exposed headers.
+var rpcExposedHeaders = []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"}
+
+// handle sets CORS headers of actual request, rpc is true for gRPC-Web and Connect requests.
+func (p *corsPolicy) handle(w http.ResponseWriter, r *http.Request, rpc bool) {
+	origin := r.Header.Get("Origin")
+	if len(origin) == 0 {
+		return
+	}
+
+	if !p.setOrigin(w.Header(), origin) {
+		return
+	}
+
+	exposed := p.ExposedHeaders
+	if rpc {
+		exposed = append(exposed[:len(exposed):len(exposed)], rpcExposedHeaders...)
+	}
+
+	if len(exposed) > 0 {
+		w.Header().Set("Access-Control-Expose-Headers", strings.Join(exposed, ", "))
+	}
+}
+
+// preflight answers preflight request for path with routes of methods, rpc is true for gRPC methods
+// served with gRPC-Web and Connect protocols. It responds 404 Not Found if there are no routes for the path.
+func (p *corsPolicy) preflight(w http.ResponseWriter, r *http.Request, methods []string, rpc bool) {
+	if len(methods) == 0 {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	h := w.Header()
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+
+	if p.setOrigin(h, r.Header.Get("Origin")) {
+		p.setPreflight(h, r, methods, rpc)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *corsPolicy) setPreflight(h http.Header, r *http.Request, methods []string, rpc bool) {
+	allowed := methods[:0:0]
+
+	for _, method := range methods {
+		if _, ok := p.allowedMethods[method]; ok || len(p.allowedMethods) == 0 {
+			allowed = append(allowed, method)
+		}
+	}
+
+	if !containsString(allowed, r.Header.Get("Access-Control-Request-Method")) {
+		return
+	}
+
+	var requested []string
+
+	for _, v := range r.Header.Values("Access-Control-Request-Headers") {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); len(s) > 0 {
+				requested = append(requested, s)
+			}
+		}
+	}
+
+	if !p.anyHeader {
+		for _, s := range requested {
+			k := http.CanonicalHeaderKey(s)
+
+			if _, ok := p.allowedHeaders[k]; ok {
+				continue
+			}
+
+			if _, ok := rpcRequestHeaders[k]; !ok || !rpc {
+				return
+			}
+		}
+	}
+
+	h.Set("Access-Control-Allow-Methods", strings.Join(allowed, ", "))
+
+	if len(requested) > 0 {
+		h.Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+	}
+
+	if p.MaxAge > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(int(p.MaxAge/time.Second)))
+	}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pathMethods returns sorted HTTP methods of routes that match url path.
+func (m *Map) pathMethods(urlPath string) []string {
+	c := runtime.AcquireCaptures()
+	defer runtime.ReleaseCaptures(c)
+
+	var mm []string
+
+	for method := range m.Methods {
+		if m.Lookup(method, urlPath, c) != nil {
+			mm = append(mm, method)
+		}
+	}
+
+	sort.Strings(mm)
+
+	return mm
+}
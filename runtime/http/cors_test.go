@@ -0,0 +1,264 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+)
+
+func TestMap_UseCORS(t *testing.T) {
+	cors := _http.CORS{
+		AllowedOrigins:        []string{"https://app.example.com", "https://*.example.org"},
+		AllowedOriginPatterns: []*regexp.Regexp{regexp.MustCompile(`^http://localhost:\d+$`)},
+		AllowedHeaders:        []string{"Content-Type", "Authorization"},
+		ExposedHeaders:        []string{"X-Request-Id"},
+		AllowCredentials:      true,
+		MaxAge:                10 * time.Minute,
+	}
+
+	tests := []struct {
+		name     string
+		cors     _http.CORS
+		method   string
+		target   string
+		header   http.Header
+		wantCode int
+		want     http.Header
+	}{
+		{
+			"preflight",
+			cors,
+			"OPTIONS",
+			"/v1/shelves/1",
+			http.Header{
+				"Origin":                         {"https://app.example.com"},
+				"Access-Control-Request-Method":  {"DELETE"},
+				"Access-Control-Request-Headers": {"content-type, authorization"},
+			},
+			http.StatusNoContent,
+			http.Header{
+				"Vary":                             {"Access-Control-Request-Method", "Access-Control-Request-Headers", "Origin"},
+				"Access-Control-Allow-Origin":      {"https://app.example.com"},
+				"Access-Control-Allow-Credentials": {"true"},
+				"Access-Control-Allow-Methods":     {"DELETE, GET"},
+				"Access-Control-Allow-Headers":     {"content-type, authorization"},
+				"Access-Control-Max-Age":           {"600"},
+			},
+		},
+		{
+			"preflight wildcard origin",
+			cors,
+			"OPTIONS",
+			"/v1/books",
+			http.Header{
+				"Origin":                        {"https://books.example.org"},
+				"Access-Control-Request-Method": {"POST"},
+			},
+			http.StatusNoContent,
+			http.Header{
+				"Vary":                             {"Access-Control-Request-Method", "Access-Control-Request-Headers", "Origin"},
+				"Access-Control-Allow-Origin":      {"https://books.example.org"},
+				"Access-Control-Allow-Credentials": {"true"},
+				"Access-Control-Allow-Methods":     {"POST"},
+				"Access-Control-Max-Age":           {"600"},
+			},
+		},
+		{
+			"preflight not registered method",
+			cors,
+			"OPTIONS",
+			"/v1/books",
+			http.Header{
+				"Origin":                        {"http://localhost:3000"},
+				"Access-Control-Request-Method": {"DELETE"},
+			},
+			http.StatusNoContent,
+			http.Header{
+				"Vary":                             {"Access-Control-Request-Method", "Access-Control-Request-Headers", "Origin"},
+				"Access-Control-Allow-Origin":      {"http://localhost:3000"},
+				"Access-Control-Allow-Credentials": {"true"},
+			},
+		},
+		{
+			"preflight not allowed method",
+			_http.CORS{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"get"}},
+			"OPTIONS",
+			"/v1/shelves/1",
+			http.Header{
+				"Origin":                        {"https://app.example.com"},
+				"Access-Control-Request-Method": {"DELETE"},
+			},
+			http.StatusNoContent,
+			http.Header{
+				"Vary":                        {"Access-Control-Request-Method", "Access-Control-Request-Headers"},
+				"Access-Control-Allow-Origin": {"*"},
+			},
+		},
+		{
+			"preflight not allowed header",
+			cors,
+			"OPTIONS",
+			"/v1/shelves/1",
+			http.Header{
+				"Origin":                         {"https://app.example.com"},
+				"Access-Control-Request-Method":  {"GET"},
+				"Access-Control-Request-Headers": {"X-Custom"},
+			},
+			http.StatusNoContent,
+			http.Header{
+				"Vary":                             {"Access-Control-Request-Method", "Access-Control-Request-Headers", "Origin"},
+				"Access-Control-Allow-Origin":      {"https://app.example.com"},
+				"Access-Control-Allow-Credentials": {"true"},
+			},
+		},
+		{
+			"preflight any header",
+			_http.CORS{AllowedOrigins: []string{"*"}, AllowedHeaders: []string{"*"}},
+			"OPTIONS",
+			"/v1/shelves/1",
+			http.Header{
+				"Origin":                         {"https://app.example.com"},
+				"Access-Control-Request-Method":  {"GET"},
+				"Access-Control-Request-Headers": {"X-Custom"},
+			},
+			http.StatusNoContent,
+			http.Header{
+				"Vary":                         {"Access-Control-Request-Method", "Access-Control-Request-Headers"},
+				"Access-Control-Allow-Origin":  {"*"},
+				"Access-Control-Allow-Methods": {"DELETE, GET"},
+				"Access-Control-Allow-Headers": {"X-Custom"},
+			},
+		},
+		{
+			"preflight not allowed origin",
+			cors,
+			"OPTIONS",
+			"/v1/shelves/1",
+			http.Header{
+				"Origin":                        {"https://example.org"},
+				"Access-Control-Request-Method": {"GET"},
+			},
+			http.StatusNoContent,
+			http.Header{
+				"Vary": {"Access-Control-Request-Method", "Access-Control-Request-Headers", "Origin"},
+			},
+		},
+		{
+			"preflight unknown path",
+			cors,
+			"OPTIONS",
+			"/v2/shelves",
+			http.Header{
+				"Origin":                        {"https://app.example.com"},
+				"Access-Control-Request-Method": {"GET"},
+			},
+			http.StatusNotFound,
+			http.Header{},
+		},
+		{
+			"actual request",
+			cors,
+			"GET",
+			"/v1/shelves/1",
+			http.Header{
+				"Origin": {"https://APP.example.com"},
+			},
+			http.StatusOK,
+			http.Header{
+				"Vary":                             {"Origin"},
+				"Access-Control-Allow-Origin":      {"https://APP.example.com"},
+				"Access-Control-Allow-Credentials": {"true"},
+				"Access-Control-Expose-Headers":    {"X-Request-Id"},
+			},
+		},
+		{
+			"actual request any origin",
+			_http.CORS{AllowedOrigins: []string{"*"}},
+			"GET",
+			"/v1/shelves/1",
+			http.Header{
+				"Origin": {"https://app.example.com"},
+			},
+			http.StatusOK,
+			http.Header{
+				"Access-Control-Allow-Origin": {"*"},
+			},
+		},
+		{
+			"actual request any origin with credentials",
+			_http.CORS{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			"GET",
+			"/v1/shelves/1",
+			http.Header{
+				"Origin": {"https://app.example.com"},
+			},
+			http.StatusOK,
+			http.Header{
+				"Vary":                             {"Origin"},
+				"Access-Control-Allow-Origin":      {"https://app.example.com"},
+				"Access-Control-Allow-Credentials": {"true"},
+			},
+		},
+		{
+			"actual request not allowed origin",
+			cors,
+			"GET",
+			"/v1/shelves/1",
+			http.Header{
+				"Origin": {"https://evil.com"},
+			},
+			http.StatusOK,
+			http.Header{
+				"Vary": {"Origin"},
+			},
+		},
+		{
+			"same origin request",
+			cors,
+			"GET",
+			"/v1/shelves/1",
+			http.Header{},
+			http.StatusOK,
+			http.Header{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) {}
+
+			m := _http.NewMap()
+			for _, route := range [][2]string{{"GET", "/v1/shelves/{id}"}, {"DELETE", "/v1/shelves/{id}"}, {"POST", "/v1/books"}} {
+				if err := m.Add(route[0], route[1], h); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			m.UseCORS(tt.cors)
+
+			r := httptest.NewRequest(tt.method, tt.target, nil)
+			r.Header = tt.header
+			w := httptest.NewRecorder()
+
+			m.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("ServeHTTP() code = %v, want %v", w.Code, tt.wantCode)
+			}
+
+			got := w.Header().Clone()
+			got.Del("Content-Type")
+			got.Del("X-Content-Type-Options")
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ServeHTTP() header = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Explanation describes how url path matches the route.
+type Explanation struct {
+	RouteInfo
+	Matched bool   `json:"matched"`
+	Reason  string `json:"reason,omitempty"` // why route is not matched
+}
+
+// Explain explains how HTTP method and url path match registered routes.
+// Routes are returned in the same order as Routes does.
+// Only the route that is returned by Lookup is marked as matched.
+func (m *Map) Explain(method string, urlPath string) []Explanation {
+	sp := strings.Split(strings.Trim(urlPath, "/"), "/")
+
+	var (
+		ee      []Explanation
+		matched *Route
+	)
+
+	for _, k := range m.methods() {
+		for _, r := range m.Methods[k] {
+			e := Explanation{RouteInfo: r.Info()}
+
+			switch {
+			case k != method:
+				e.Reason = fmt.Sprintf("HTTP method '%s' is not '%s'", method, k)
+			case matched != nil:
+				if err := r.Path.Explain(sp); err != nil {
+					e.Reason = err.Error()
+				} else {
+					e.Reason = fmt.Sprintf("shadowed by route '%s' with higher precedence", matched.Path)
+				}
+			default:
+				if err := r.Path.Explain(sp); err != nil {
+					e.Reason = err.Error()
+				} else {
+					e.Matched = true
+					matched = r
+				}
+			}
+
+			ee = append(ee, e)
+		}
+	}
+
+	return ee
+}
+
+// DebugHandler returns http.Handler that serves registered routes as JSON.
+// If "path" query parameter is set, it serves explanation of how "path"
+// and "method" (GET by default) match the routes instead.
+// It is intended for debugging and should not be exposed publicly.
+func (m *Map) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var v interface{}
+
+		q := r.URL.Query()
+		if path := q.Get("path"); len(path) > 0 {
+			method := q.Get("method")
+			if len(method) == 0 {
+				method = http.MethodGet
+			}
+
+			v = struct {
+				Method       string        `json:"method"`
+				Path         string        `json:"path"`
+				Explanations []Explanation `json:"explanations"`
+			}{method, path, m.Explain(method, path)}
+		} else {
+			v = struct {
+				Routes []RouteInfo `json:"routes"`
+			}{m.Routes()}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		_ = e.Encode(v)
+	})
+}
@@ -0,0 +1,100 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+)
+
+func TestMap_Explain(t *testing.T) {
+	m := _http.NewMap()
+
+	h := func(context.Context, http.ResponseWriter, *http.Request) {}
+
+	for _, p := range []struct {
+		method   string
+		template string
+	}{
+		{"GET", "/v1/articles/{value}"},
+		{"GET", "/v1/articles/{value=**}"},
+		{"GET", "/v1/books"},
+		{"POST", "/v1/articles/{value}"},
+	} {
+		if err := m.Add(p.method, p.template, h); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type result struct {
+		Template string
+		Matched  bool
+		Reason   string
+	}
+
+	want := []result{
+		{"/v1/books", false, "url path does not match template: segment 2: want 'books', got 'articles'"},
+		{"/v1/articles/{value}", true, ""},
+		{"/v1/articles/{value=**}", false, "shadowed by route '/v1/articles/{value}' with higher precedence"},
+		{"/v1/articles/{value}", false, "HTTP method 'GET' is not 'POST'"},
+	}
+
+	var got []result
+	for _, e := range m.Explain("GET", "/v1/articles/12345") {
+		got = append(got, result{e.Template, e.Matched, e.Reason})
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Map.Explain() = %v, want %v", got, want)
+	}
+}
+
+func TestMap_DebugHandler(t *testing.T) {
+	m := _http.NewMap()
+
+	if err := m.Add("GET", "/v1/articles/{value}", func(context.Context, http.ResponseWriter, *http.Request) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{
+			"routes",
+			"/debug/routes",
+			"routes",
+		},
+		{
+			"explanations",
+			"/debug/routes?path=/v1/articles/12345",
+			"explanations",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			m.DebugHandler().ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("DebugHandler() Content-Type = %v, want application/json", ct)
+			}
+
+			var got map[string]json.RawMessage
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, ok := got[tt.want]; !ok {
+				t.Errorf("DebugHandler() = %s, want '%s' key", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/amsokol/protobuf-rest/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// DecodeRequest populates request message from HTTP request body, query parameters
+// and path values of the context. body is field path of request message
+// that is bound to HTTP request body, "*" if the whole message is bound and empty
+// string if there is no body. Query parameters are ignored if body is "*".
+// Request body is decoded within Limits of the route. Request body bound to google.api.HttpBody
+// is not decoded, its data and content type are set from request as is.
+// Marshalers that implement RequestUnmarshaler, e.g. MultipartMarshaler, decode request body from the request.
+// Required fields of proto2 messages are checked after all sources are decoded,
+// so marshalers should unmarshal partial messages, see DefaultMarshaler.
+// It is used by generated code.
+func DecodeRequest(ctx context.Context, r *http.Request, req proto.Message, body string, opts ...DecodeOption) error {
+	var o decodeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var data []byte
+
+	if len(body) > 0 {
+		var err error
+
+		if data, err = decodeBody(ctx, r, req, body); err != nil {
+			if _, ok := status.FromError(err); ok {
+				// limits are exceeded
+				return err
+			}
+
+			return status.Errorf(codes.InvalidArgument, "decode request body: %v", err)
+		}
+	}
+
+	vv := ValuesFromContext(ctx)
+
+	if body != "*" {
+		skip := make([]string, 0, len(vv)+1)
+		for k := range vv {
+			skip = append(skip, k)
+		}
+
+		if len(body) > 0 {
+			skip = append(skip, body)
+		}
+
+		if err := runtime.PopulateQuery(req, r.URL.Query(), skip...); err != nil {
+			return status.Errorf(codes.InvalidArgument, "decode query parameters: %v", err)
+		}
+	}
+
+	if err := vv.Populate(req); err != nil {
+		return status.Errorf(codes.InvalidArgument, "decode path values: %v", err)
+	}
+
+	if err := proto.CheckInitialized(req); err != nil {
+		return status.Errorf(codes.InvalidArgument, "decode request: %v", err)
+	}
+
+	if len(o.updateMask) > 0 && len(data) > 0 && body != "*" && isJSON(MarshalerForRequest(ctx, r)) {
+		if err := populateUpdateMask(req, body, o.updateMask, data); err != nil {
+			return status.Errorf(codes.InvalidArgument, "populate update mask: %v", err)
+		}
+	}
+
+	setRequestMessage(ctx, req)
+
+	return nil
+}
+
+// DecodeOption configures DecodeRequest.
+type DecodeOption func(*decodeOptions)
+
+type decodeOptions struct {
+	updateMask string
+}
+
+// WithUpdateMask makes DecodeRequest populate google.protobuf.FieldMask field updateMask of request
+// message with paths of body fields present in JSON request body, if the client did not set the mask.
+// See https://google.aip.dev/134. Empty updateMask disables it.
+// It is used by generated code for PATCH bindings.
+func WithUpdateMask(updateMask string) DecodeOption {
+	return func(o *decodeOptions) {
+		o.updateMask = updateMask
+	}
+}
+
+func populateUpdateMask(req proto.Message, body string, updateMask string, data []byte) error {
+	md := req.ProtoReflect().Descriptor()
+
+	mfds, err := runtime.FieldByPath(md, updateMask)
+	if err != nil {
+		return err
+	}
+
+	m := req.ProtoReflect()
+	for _, fd := range mfds[:len(mfds)-1] {
+		m = m.Mutable(fd).Message()
+	}
+
+	mfd := mfds[len(mfds)-1]
+
+	if mfd.Message() == nil || mfd.Message().FullName() != "google.protobuf.FieldMask" || mfd.IsList() {
+		return fmt.Errorf("field '%s' is not google.protobuf.FieldMask", updateMask)
+	}
+
+	if m.Has(mfd) {
+		return nil
+	}
+
+	bfds, err := runtime.FieldByPath(md, body)
+	if err != nil {
+		return err
+	}
+
+	bfd := bfds[len(bfds)-1]
+	if bfd.Message() == nil || bfd.IsList() || bfd.IsMap() {
+		return fmt.Errorf("body field '%s' is not message", body)
+	}
+
+	pp, err := runtime.FieldMaskFromJSON(bfd.Message(), data)
+	if err != nil {
+		return err
+	}
+
+	m.Set(mfd, protoreflect.ValueOfMessage((&fieldmaskpb.FieldMask{Paths: pp}).ProtoReflect()))
+
+	return nil
+}
+
+func isJSON(mar Marshaler) bool {
+	return strings.Contains(mar.ContentType(), "json")
+}
+
+func decodeBody(ctx context.Context, r *http.Request, req proto.Message, body string) ([]byte, error) {
+	l := RouteFromContext(ctx).Limits()
+	mar := MarshalerForRequest(ctx, r)
+
+	if ru, ok := mar.(RequestUnmarshaler); ok && !isHTTPBodyBinding(req, body) {
+		m, err := bodyMessage(req, body)
+		if err != nil {
+			return nil, err
+		}
+
+		// form data is not JSON, update mask is not populated from it
+		return nil, ru.UnmarshalRequest(r, l, m)
+	}
+
+	data, err := l.readBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	if hb := requestHTTPBody(req, body); hb != nil {
+		// raw request body is passed as is
+		hb.ContentType = r.Header.Get("Content-Type")
+		hb.Data = data
+
+		return nil, nil
+	}
+
+	if isJSON(mar) {
+		if err := l.checkJSON(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if body == "*" {
+		return data, mar.Unmarshal(data, req)
+	}
+
+	fds, err := runtime.FieldByPath(req.ProtoReflect().Descriptor(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	m := req.ProtoReflect()
+	for _, fd := range fds[:len(fds)-1] {
+		m = m.Mutable(fd).Message()
+	}
+
+	fd := fds[len(fds)-1]
+
+	if fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+		return data, mar.Unmarshal(data, m.Mutable(fd).Message().Interface())
+	}
+
+	// scalar, repeated and map fields are decoded as JSON value of the field
+	v, err := json.Marshal(map[string]json.RawMessage{fd.JSONName(): data})
+	if err != nil {
+		return nil, err
+	}
+
+	tmp := m.New()
+	if err := (protojson.UnmarshalOptions{AllowPartial: true}).Unmarshal(v, tmp.Interface()); err != nil {
+		return nil, fmt.Errorf("decode field '%s': %w", body, err)
+	}
+
+	// JSON null keeps the field unpopulated
+	if tmp.Has(fd) {
+		m.Set(fd, tmp.Get(fd))
+	}
+
+	return data, nil
+}
+
+// bodyMessage returns message of request bound to request body.
+func bodyMessage(req proto.Message, body string) (proto.Message, error) {
+	if body == "*" {
+		return req, nil
+	}
+
+	fds, err := runtime.FieldByPath(req.ProtoReflect().Descriptor(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	fd := fds[len(fds)-1]
+	if fd.Message() == nil || fd.IsList() || fd.IsMap() {
+		return nil, fmt.Errorf("body field '%s' is not message", body)
+	}
+
+	m := req.ProtoReflect()
+	for _, fd := range fds {
+		m = m.Mutable(fd).Message()
+	}
+
+	return m.Interface(), nil
+}
+
+// MetadataHeaderPrefix is prefix of HTTP headers that are passed as gRPC metadata with the prefix removed.
+const MetadataHeaderPrefix = "Grpc-Metadata-"
+
+// HeaderMatcher returns gRPC metadata key of HTTP request header with canonical key
+// and whether the header is passed to service as incoming metadata.
+type HeaderMatcher func(key string) (string, bool)
+
+// DefaultHeaderMatcher passes headers with MetadataHeaderPrefix with the prefix removed and permanent HTTP headers,
+// e.g. Authorization, Accept-Language or User-Agent, and RequestIDHeader. Other headers are not passed,
+// so clients cannot set arbitrary metadata that interceptors and services trust.
+func DefaultHeaderMatcher(key string) (string, bool) {
+	if strings.HasPrefix(key, MetadataHeaderPrefix) {
+		return strings.ToLower(strings.TrimPrefix(key, MetadataHeaderPrefix)), true
+	}
+
+	if _, ok := _permanentHeaders[key]; ok {
+		return strings.ToLower(key), true
+	}
+
+	return "", false
+}
+
+// UseHeaderMatcher sets matcher of HTTP request headers of all routes that are passed to services
+// as incoming gRPC metadata, DefaultHeaderMatcher is used by default.
+func (m *Map) UseHeaderMatcher(hm HeaderMatcher) {
+	m.headerMatcher = hm
+}
+
+// AnnotateContext adds HTTP request headers to the context as incoming gRPC metadata,
+// so gRPC interceptors and service implementation can read them with metadata.FromIncomingContext.
+// Headers are selected by header matcher of the Map of the route of the context, see UseHeaderMatcher,
+// hop-by-hop headers are skipped.
+// The context also accepts outgoing metadata set with grpc.SetHeader and grpc.SetTrailer,
+// WriteResponse and WriteError apply it to HTTP response.
+// It is used by generated code.
+func AnnotateContext(ctx context.Context, r *http.Request) context.Context {
+	match := DefaultHeaderMatcher
+	if route := RouteFromContext(ctx); route != nil && route.m != nil && route.m.headerMatcher != nil {
+		match = route.m.headerMatcher
+	}
+
+	md := make(metadata.MD, len(r.Header))
+
+	for k, vv := range r.Header {
+		k = textproto.CanonicalMIMEHeaderKey(k)
+
+		if _, ok := _hopByHopHeaders[k]; ok {
+			continue
+		}
+
+		if key, ok := match(k); ok {
+			md.Append(key, vv...)
+		}
+	}
+
+	return newServerTransportStreamContext(metadata.NewIncomingContext(ctx, md))
+}
+
+// _permanentHeaders are HTTP headers passed by DefaultHeaderMatcher.
+var _permanentHeaders = map[string]struct{}{
+	"Accept":                {},
+	"Accept-Charset":        {},
+	"Accept-Language":       {},
+	"Accept-Ranges":         {},
+	"Authorization":         {},
+	"Cache-Control":         {},
+	"Content-Type":          {},
+	"Cookie":                {},
+	"Date":                  {},
+	"Expect":                {},
+	"From":                  {},
+	"Host":                  {},
+	"If-Match":              {},
+	"If-Modified-Since":     {},
+	"If-None-Match":         {},
+	"If-Schedule-Tag-Match": {},
+	"If-Unmodified-Since":   {},
+	"Max-Forwards":          {},
+	"Origin":                {},
+	"Pragma":                {},
+	"Referer":               {},
+	"User-Agent":            {},
+	"Via":                   {},
+	"Warning":               {},
+	RequestIDHeader:         {},
+}
+
+var _hopByHopHeaders = map[string]struct{}{
+	"Connection":          {},
+	"Keep-Alive":          {},
+	"Proxy-Authenticate":  {},
+	"Proxy-Authorization": {},
+	"Proxy-Connection":    {},
+	"Te":                  {},
+	"Trailer":             {},
+	"Transfer-Encoding":   {},
+	"Upgrade":             {},
+	"Content-Length":      {},
+}
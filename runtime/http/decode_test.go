@@ -0,0 +1,400 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	library "google.golang.org/genproto/googleapis/example/library/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	type args struct {
+		body   string
+		target string
+		data   string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		want     proto.Message
+		wantCode codes.Code
+	}{
+		{
+			"no body",
+			args{
+				"",
+				"/v1/rules/example.Service.Method?responseBody=data&custom.kind=HEAD",
+				"",
+			},
+			&annotations.HttpRule{
+				Selector:     "example.Service.Method",
+				ResponseBody: "data",
+				Pattern:      &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Kind: "HEAD"}},
+			},
+			codes.OK,
+		},
+		{
+			"whole body",
+			args{
+				"*",
+				"/v1/rules/example.Service.Method?responseBody=ignored",
+				`{"selector": "overridden", "get": "/v1/items", "unknown": true}`,
+			},
+			&annotations.HttpRule{
+				Selector: "example.Service.Method",
+				Pattern:  &annotations.HttpRule_Get{Get: "/v1/items"},
+			},
+			codes.OK,
+		},
+		{
+			"message field body",
+			args{
+				"custom",
+				"/v1/rules/example.Service.Method?custom.kind=ignored&body=data",
+				`{"kind": "HEAD", "path": "/v1/items"}`,
+			},
+			&annotations.HttpRule{
+				Selector: "example.Service.Method",
+				Body:     "data",
+				Pattern:  &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Kind: "HEAD", Path: "/v1/items"}},
+			},
+			codes.OK,
+		},
+		{
+			"repeated field body",
+			args{
+				"additional_bindings",
+				"/v1/rules/example.Service.Method",
+				`[{"get": "/v1/items"}, {"post": "/v1/items"}]`,
+			},
+			&annotations.HttpRule{
+				Selector: "example.Service.Method",
+				AdditionalBindings: []*annotations.HttpRule{
+					{Pattern: &annotations.HttpRule_Get{Get: "/v1/items"}},
+					{Pattern: &annotations.HttpRule_Post{Post: "/v1/items"}},
+				},
+			},
+			codes.OK,
+		},
+		{
+			"invalid body",
+			args{
+				"*",
+				"/v1/rules/example.Service.Method",
+				`{"selector": 1}`,
+			},
+			nil,
+			codes.InvalidArgument,
+		},
+		{
+			"invalid query",
+			args{
+				"",
+				"/v1/rules/example.Service.Method?selector.value=1",
+				"",
+			},
+			nil,
+			codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &annotations.HttpRule{}
+
+			m := _http.NewMap()
+			if err := m.Add("POST", "/v1/rules/{selector}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				if err := _http.DecodeRequest(ctx, r, got, tt.args.body); status.Code(err) != tt.wantCode {
+					t.Errorf("DecodeRequest() error = %v, want %v", err, tt.wantCode)
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", tt.args.target, strings.NewReader(tt.args.data)))
+
+			if tt.want != nil && !proto.Equal(got, tt.want) {
+				t.Errorf("DecodeRequest() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRequest_UpdateMask(t *testing.T) {
+	type args struct {
+		updateMask string
+		target     string
+		data       string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		want     proto.Message
+		wantCode codes.Code
+	}{
+		{
+			"from body",
+			args{
+				"update_mask",
+				"/v1/shelves/1/books/2",
+				`{"title": "Dune", "read": false}`,
+			},
+			&library.UpdateBookRequest{
+				Book:       &library.Book{Name: "shelves/1/books/2", Title: "Dune"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"read", "title"}},
+			},
+			codes.OK,
+		},
+		{
+			"from query",
+			args{
+				"update_mask",
+				"/v1/shelves/1/books/2?updateMask=author",
+				`{"title": "Dune", "author": "Frank Herbert"}`,
+			},
+			&library.UpdateBookRequest{
+				Book:       &library.Book{Name: "shelves/1/books/2", Title: "Dune", Author: "Frank Herbert"},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"author"}},
+			},
+			codes.OK,
+		},
+		{
+			"disabled",
+			args{
+				"",
+				"/v1/shelves/1/books/2",
+				`{"title": "Dune"}`,
+			},
+			&library.UpdateBookRequest{
+				Book: &library.Book{Name: "shelves/1/books/2", Title: "Dune"},
+			},
+			codes.OK,
+		},
+		{
+			"empty body",
+			args{
+				"update_mask",
+				"/v1/shelves/1/books/2",
+				"",
+			},
+			&library.UpdateBookRequest{
+				Book: &library.Book{Name: "shelves/1/books/2"},
+			},
+			codes.OK,
+		},
+		{
+			"not field mask",
+			args{
+				"book",
+				"/v1/shelves/1/books/2",
+				`{"title": "Dune"}`,
+			},
+			nil,
+			codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &library.UpdateBookRequest{}
+
+			m := _http.NewMap()
+			if err := m.Add("PATCH", "/v1/{book.name=shelves/*/books/*}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				err := _http.DecodeRequest(ctx, r, got, "book", _http.WithUpdateMask(tt.args.updateMask))
+				if status.Code(err) != tt.wantCode {
+					t.Errorf("DecodeRequest() error = %v, want %v", err, tt.wantCode)
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("PATCH", tt.args.target, strings.NewReader(tt.args.data)))
+
+			if tt.want != nil && !proto.Equal(got, tt.want) {
+				t.Errorf("DecodeRequest() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRequest_proto2(t *testing.T) {
+	type args struct {
+		template string
+		body     string
+		target   string
+		data     string
+	}
+
+	tests := []struct {
+		name     string
+		args     args
+		got      proto.Message
+		want     proto.Message
+		wantCode codes.Code
+	}{
+		{
+			"required fields from body and path",
+			args{
+				"/v1/parts/{name_part}",
+				"*",
+				"/v1/parts/ext",
+				`{"isExtension": true}`,
+			},
+			&descriptorpb.UninterpretedOption_NamePart{},
+			&descriptorpb.UninterpretedOption_NamePart{NamePart: proto.String("ext"), IsExtension: proto.Bool(true)},
+			codes.OK,
+		},
+		{
+			"missing required field",
+			args{
+				"/v1/parts/{name_part}",
+				"*",
+				"/v1/parts/ext",
+				`{}`,
+			},
+			&descriptorpb.UninterpretedOption_NamePart{},
+			nil,
+			codes.InvalidArgument,
+		},
+		{
+			"scalar field body presence",
+			args{
+				"/v1/options",
+				"deprecated",
+				"/v1/options",
+				`false`,
+			},
+			&descriptorpb.FieldOptions{},
+			&descriptorpb.FieldOptions{Deprecated: proto.Bool(false)},
+			codes.OK,
+		},
+		{
+			"scalar field body null",
+			args{
+				"/v1/options",
+				"deprecated",
+				"/v1/options",
+				`null`,
+			},
+			&descriptorpb.FieldOptions{},
+			&descriptorpb.FieldOptions{},
+			codes.OK,
+		},
+		{
+			"extension query parameter",
+			args{
+				"/v1/options",
+				"",
+				"/v1/options?%5Bgoogle.api.field_behavior%5D=OUTPUT_ONLY&deprecated=true",
+				"",
+			},
+			&descriptorpb.FieldOptions{},
+			func() proto.Message {
+				o := &descriptorpb.FieldOptions{Deprecated: proto.Bool(true)}
+				proto.SetExtension(o, annotations.E_FieldBehavior, []annotations.FieldBehavior{annotations.FieldBehavior_OUTPUT_ONLY})
+
+				return o
+			}(),
+			codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := _http.NewMap()
+			if err := m.Add("POST", tt.args.template, func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				if err := _http.DecodeRequest(ctx, r, tt.got, tt.args.body); status.Code(err) != tt.wantCode {
+					t.Errorf("DecodeRequest() error = %v, want %v", err, tt.wantCode)
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", tt.args.target, strings.NewReader(tt.args.data)))
+
+			if tt.want != nil && !proto.Equal(tt.got, tt.want) {
+				t.Errorf("DecodeRequest() got = %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnnotateContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher _http.HeaderMatcher // matcher of the Map, optional
+		want    metadata.MD
+	}{
+		{
+			"default",
+			nil,
+			metadata.MD{
+				"authorization": {"Bearer token"},
+				"user-agent":    {"test"},
+				"x-request-id":  {"abc"},
+				"request-id":    {"12345"},
+				"locale":        {"en"},
+			},
+		},
+		{
+			"custom",
+			func(key string) (string, bool) {
+				if key == "X-Tenant" {
+					return "tenant", true
+				}
+
+				return "", false
+			},
+			metadata.MD{
+				"tenant": {"acme"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := _http.NewMap()
+			if tt.matcher != nil {
+				m.UseHeaderMatcher(tt.matcher)
+			}
+
+			var md metadata.MD
+
+			if err := m.Add("GET", "/v1/articles", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				md, _ = metadata.FromIncomingContext(_http.AnnotateContext(ctx, r))
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest("GET", "/v1/articles", nil)
+			r.Header.Set("Authorization", "Bearer token")
+			r.Header.Set("User-Agent", "test")
+			r.Header.Set("X-Request-Id", "abc")
+			r.Header.Set("Grpc-Metadata-Request-Id", "12345")
+			r.Header.Set("Grpc-Metadata-Locale", "en")
+			r.Header.Set("X-User-Id", "admin")
+			r.Header.Set("X-Tenant", "acme")
+			r.Header.Set("Connection", "keep-alive")
+
+			m.ServeHTTP(httptest.NewRecorder(), r)
+
+			if !reflect.DeepEqual(md, tt.want) {
+				t.Errorf("AnnotateContext() = %v, want %v", md, tt.want)
+			}
+		})
+	}
+}
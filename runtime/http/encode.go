@@ -0,0 +1,173 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/amsokol/protobuf-rest/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// WriteResponse writes response message to HTTP response. responseBody is field path
+// of response message that is written instead of the whole message, empty string
+// means the whole message. HTTP status code and headers may be set by service implementation
+// with grpc.SetHeader, see HTTPCodeMetadataKey and HTTPHeaderMetadataPrefix.
+// google.api.HttpBody response is written as raw data with its content type.
+// It is used by generated code.
+func WriteResponse(ctx context.Context, w http.ResponseWriter, r *http.Request, resp proto.Message, responseBody string) {
+	if hb, ok := responseHTTPBody(resp, responseBody); ok {
+		// raw response body is written as is
+		writeHTTPBodyHeader(ctx, w, hb)
+		_, _ = w.Write(hb.GetData())
+
+		return
+	}
+
+	mar := MarshalerForRequest(ctx, r)
+
+	var (
+		data []byte
+		err  error
+	)
+
+	if len(responseBody) > 0 {
+		data, err = marshalField(mar, resp, responseBody)
+	} else {
+		data, err = mar.Marshal(resp)
+	}
+
+	if err != nil {
+		WriteError(ctx, w, r, status.Errorf(codes.Internal, "marshal response: %v", err))
+
+		return
+	}
+
+	code := writeMetadata(ctx, w)
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	w.Header().Set("Content-Type", mar.ContentType())
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
+
+func marshalField(mar Marshaler, resp proto.Message, responseBody string) ([]byte, error) {
+	fds, err := runtime.FieldByPath(resp.ProtoReflect().Descriptor(), responseBody)
+	if err != nil {
+		return nil, err
+	}
+
+	m := resp.ProtoReflect()
+	for _, fd := range fds[:len(fds)-1] {
+		m = m.Get(fd).Message()
+	}
+
+	fd := fds[len(fds)-1]
+
+	if fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+		return mar.Marshal(m.Get(fd).Message().Interface())
+	}
+
+	// scalar, repeated and map fields are encoded as JSON value of the field,
+	// it is taken from the message with the only field marshaled by mar
+	tmp := m.New()
+	tmp.Set(fd, m.Get(fd))
+
+	data, err := fieldMarshaler(mar, fd).Marshal(tmp.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	var v map[string]json.RawMessage
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("field '%s' requires JSON marshaler: %w", responseBody, err)
+	}
+
+	if f, ok := v[fd.JSONName()]; ok {
+		return f, nil
+	}
+
+	if f, ok := v[string(fd.Name())]; ok {
+		return f, nil
+	}
+
+	// unpopulated field is omitted by marshaler
+	switch {
+	case fd.IsMap():
+		return []byte("{}"), nil
+	case fd.IsList():
+		return []byte("[]"), nil
+	}
+
+	return []byte("null"), nil
+}
+
+// fieldMarshaler returns JSON marshaler with options of mar that emits unpopulated scalar fields as zero
+// or proto2 default values and allows partial messages. Messages of repeated and map fields are marshaled as is.
+// Other marshalers are returned unchanged.
+func fieldMarshaler(mar Marshaler, fd protoreflect.FieldDescriptor) Marshaler {
+	j, ok := mar.(*JSONMarshaler)
+	if !ok {
+		return mar
+	}
+
+	mo := j.MarshalOptions
+	mo.EmitUnpopulated = mo.EmitUnpopulated || (!fd.IsList() && !fd.IsMap())
+	mo.AllowPartial = true
+
+	return &JSONMarshaler{MarshalOptions: mo, UnmarshalOptions: j.UnmarshalOptions}
+}
+
+// WriteError writes error to HTTP response as google.rpc.Status message
+// with HTTP status code converted from gRPC status code or set by HTTPError. HTTP headers set by service
+// implementation are applied as well.
+// It is used by generated code.
+func WriteError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	var s *status.Status
+
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		s = status.FromContextError(err)
+	default:
+		s = status.Convert(err)
+	}
+
+	setResponseCode(ctx, s.Code())
+
+	if we, ok := ctx.Value(rpcErrorWriterKey{}).(rpcErrorWriter); ok {
+		// gRPC-Web or Connect request, see GRPCHandler
+		we(w, r, s)
+
+		return
+	}
+
+	mar := MarshalerForRequest(ctx, r)
+
+	data, merr := mar.Marshal(s.Proto())
+	if merr != nil {
+		http.Error(w, fmt.Sprintf("%s: %s", s.Code(), s.Message()), http.StatusInternalServerError)
+
+		return
+	}
+
+	// HTTP status code of error can't be changed by service implementation
+	_ = writeMetadata(ctx, w)
+
+	code := HTTPStatusFromCode(s.Code())
+
+	var he *HTTPError
+	if errors.As(err, &he) {
+		code = he.HTTPStatus
+	}
+
+	w.Header().Set("Content-Type", mar.ContentType())
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,246 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestWriteResponse(t *testing.T) {
+	resp := &annotations.HttpRule{
+		Selector: "example.Service.Method",
+		Pattern:  &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Kind: "HEAD"}},
+		AdditionalBindings: []*annotations.HttpRule{
+			{Pattern: &annotations.HttpRule_Get{Get: "/v1/items"}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		responseBody string
+		want         string
+	}{
+		{
+			"whole message",
+			"",
+			`{"selector":"example.Service.Method","custom":{"kind":"HEAD"},"additionalBindings":[{"get":"/v1/items"}]}`,
+		},
+		{
+			"message field",
+			"custom",
+			`{"kind":"HEAD"}`,
+		},
+		{
+			"repeated field",
+			"additional_bindings",
+			`[{"get":"/v1/items"}]`,
+		},
+		{
+			"scalar field",
+			"selector",
+			`"example.Service.Method"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			_http.WriteResponse(context.Background(), w, httptest.NewRequest("GET", "/", nil), resp, tt.responseBody)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("WriteResponse() code = %v, want %v", w.Code, http.StatusOK)
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("WriteResponse() Content-Type = %v, want application/json", ct)
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("WriteResponse() = %s, want %s", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteResponse_proto2(t *testing.T) {
+	tests := []struct {
+		name         string
+		resp         proto.Message
+		responseBody string
+		want         string
+	}{
+		{
+			"default value",
+			&descriptorpb.FileOptions{},
+			"optimize_for",
+			`"SPEED"`,
+		},
+		{
+			"partial message",
+			&descriptorpb.UninterpretedOption_NamePart{NamePart: proto.String("ext")},
+			"name_part",
+			`"ext"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			_http.WriteResponse(context.Background(), w, httptest.NewRequest("GET", "/", nil), tt.resp, tt.responseBody)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("WriteResponse() code = %v, want %v, body %s", w.Code, http.StatusOK, w.Body.String())
+			}
+
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("WriteResponse() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteResponse_marshaler(t *testing.T) {
+	tests := []struct {
+		name         string
+		mar          _http.Marshaler
+		resp         proto.Message
+		responseBody string
+		want         string
+	}{
+		{
+			"enum numbers",
+			&_http.JSONMarshaler{MarshalOptions: protojson.MarshalOptions{UseEnumNumbers: true}},
+			&descriptorpb.FileOptions{},
+			"optimize_for",
+			`1`,
+		},
+		{
+			"proto names",
+			_http.ProtoNamesMarshaler,
+			&descriptorpb.FileDescriptorProto{Dependency: []string{"a.proto"}},
+			"dependency",
+			`["a.proto"]`,
+		},
+		{
+			"proto names repeated message",
+			_http.ProtoNamesMarshaler,
+			&descriptorpb.FileDescriptorProto{MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("Item"), ReservedName: []string{"id"}}}},
+			"message_type",
+			`[{"name":"Item","reserved_name":["id"]}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := _http.NewMap()
+
+			if err := m.Add("GET", "/v1/items", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				_http.WriteResponse(ctx, w, r, tt.resp, tt.responseBody)
+			}, _http.WithMarshaler(tt.mar)); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, httptest.NewRequest("GET", "/v1/items", nil))
+
+			if w.Code != http.StatusOK {
+				t.Errorf("WriteResponse() code = %v, want %v, body %s", w.Code, http.StatusOK, w.Body.String())
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("WriteResponse() = %s, want %s", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		want     string
+	}{
+		{
+			"status",
+			status.Error(codes.NotFound, "article is not found"),
+			http.StatusNotFound,
+			`{"code":5,"message":"article is not found"}`,
+		},
+		{
+			"error",
+			errors.New("failure"),
+			http.StatusInternalServerError,
+			`{"code":2,"message":"failure"}`,
+		},
+		{
+			"context",
+			context.DeadlineExceeded,
+			http.StatusGatewayTimeout,
+			`{"code":4,"message":"context deadline exceeded"}`,
+		},
+		{
+			"HTTP error",
+			&_http.HTTPError{HTTPStatus: http.StatusRequestEntityTooLarge, Err: status.Error(codes.ResourceExhausted, "too large")},
+			http.StatusRequestEntityTooLarge,
+			`{"code":8,"message":"too large"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			_http.WriteError(context.Background(), w, httptest.NewRequest("GET", "/", nil), tt.err)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("WriteError() code = %v, want %v", w.Code, tt.wantCode)
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("WriteError() = %s, want %s", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/amsokol/protobuf-rest/runtime"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// FormContentType is content type of HTML form requests.
+	FormContentType = "application/x-www-form-urlencoded"
+	// MultipartContentType is content type of multipart form requests, e.g. file uploads.
+	MultipartContentType = "multipart/form-data"
+)
+
+// FormMarshaler is Marshaler of "application/x-www-form-urlencoded" requests. Form fields are field paths
+// of the message bound to request body and are set with the same rules as query parameters,
+// unknown fields are ignored. Responses are encoded with Marshaler.
+//
+//	m.RegisterMarshaler(http.FormContentType, &http.FormMarshaler{})
+type FormMarshaler struct {
+	Marshaler Marshaler // encodes responses, DefaultMarshaler if nil
+}
+
+func (f *FormMarshaler) ContentType() string {
+	return responseMarshaler(f.Marshaler).ContentType()
+}
+
+func (f *FormMarshaler) Marshal(m proto.Message) ([]byte, error) {
+	return responseMarshaler(f.Marshaler).Marshal(m)
+}
+
+func (f *FormMarshaler) Unmarshal(data []byte, m proto.Message) error {
+	form, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+
+	return runtime.PopulateQuery(m, form)
+}
+
+func (f *FormMarshaler) UnmarshalRequest(r *http.Request, l Limits, m proto.Message) error {
+	data, err := l.readBody(r)
+	if err != nil {
+		return err
+	}
+
+	return f.Unmarshal(data, m)
+}
+
+// Defaults of MultipartMarshaler.
+const (
+	DefaultMultipartMaxMemory    = 32 << 20 // the same as default of http.Request.ParseMultipartForm
+	DefaultMultipartMaxParts     = 1000
+	DefaultMultipartMaxPartsSize = 32 << 20
+)
+
+// MultipartMarshaler is Marshaler of "multipart/form-data" requests. Text parts are set to fields
+// of the message bound to request body by part names with the same rules as query parameters.
+// File parts, i.e. parts with file name, are set to bytes fields or to google.api.HttpBody fields
+// with content type of the part. Parts of unknown fields are ignored. Responses are encoded with Marshaler.
+// Total size of request body is limited by MaxBodySize of the route limits.
+//
+// The decoded message holds whole text and file parts in memory, so memory of the request is bounded
+// by MaxPartsSize. Temporary files bound memory used while the body is read only, file parts written
+// to them are read back to the message.
+//
+//	m.RegisterMarshaler(http.MultipartContentType, &http.MultipartMarshaler{TempDir: "/var/tmp/uploads"})
+type MultipartMarshaler struct {
+	Marshaler Marshaler // encodes responses, DefaultMarshaler if nil
+
+	// MaxMemory is maximum total size of file parts kept in memory while request is read, the rest of file parts
+	// is written to temporary files in TempDir that are removed after decoding. Zero means DefaultMultipartMaxMemory,
+	// negative value - all file parts are written to temporary files.
+	MaxMemory int64
+	// TempDir is directory of temporary files, os.TempDir() if empty.
+	TempDir string
+	// MaxParts is maximum number of parts, exceeding it responds 400 Bad Request.
+	// Zero means DefaultMultipartMaxParts, negative value - no limit.
+	MaxParts int
+	// MaxPartsSize is maximum total size of text and file parts of the request in bytes, exceeding it responds
+	// 413 Request Entity Too Large. Zero means DefaultMultipartMaxPartsSize, negative value - no limit
+	// except MaxBodySize of the route limits. Routes of large uploads must raise it with MaxBodySize.
+	MaxPartsSize int64
+}
+
+func (mm *MultipartMarshaler) ContentType() string {
+	return responseMarshaler(mm.Marshaler).ContentType()
+}
+
+func (mm *MultipartMarshaler) Marshal(m proto.Message) ([]byte, error) {
+	return responseMarshaler(mm.Marshaler).Marshal(m)
+}
+
+// Unmarshal is not supported as boundary of parts is parameter of content type, see UnmarshalRequest.
+func (mm *MultipartMarshaler) Unmarshal(data []byte, m proto.Message) error {
+	return errors.New("multipart body must be decoded from HTTP request")
+}
+
+func (mm *MultipartMarshaler) UnmarshalRequest(r *http.Request, l Limits, m proto.Message) (err error) {
+	_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+
+	boundary := params["boundary"]
+	if len(boundary) == 0 {
+		return errors.New("no multipart boundary")
+	}
+
+	body, err := l.limitBody(r)
+	if err != nil {
+		return err
+	}
+
+	var (
+		form  = make(url.Values)
+		files []*formFile
+	)
+
+	defer func() {
+		for _, f := range files {
+			if rerr := f.remove(); rerr != nil && err == nil {
+				err = rerr
+			}
+		}
+	}()
+
+	maxParts := mm.MaxParts
+	if maxParts == 0 {
+		maxParts = DefaultMultipartMaxParts
+	}
+
+	memory := mm.MaxMemory
+	if memory == 0 {
+		memory = DefaultMultipartMaxMemory
+	}
+
+	size := &partsSize{max: mm.MaxPartsSize}
+	if size.max == 0 {
+		size.max = DefaultMultipartMaxPartsSize
+	}
+
+	md := m.ProtoReflect().Descriptor()
+	mr := multipart.NewReader(body, boundary)
+
+	for n := 1; ; n++ {
+		p, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return bodyError(err)
+		}
+
+		if maxParts >= 0 && n > maxParts {
+			return status.Errorf(codes.InvalidArgument, "multipart body has more than %d parts", maxParts)
+		}
+
+		name := p.FormName()
+
+		if len(p.FileName()) == 0 {
+			v, err := io.ReadAll(size.limit(p))
+			if err != nil {
+				return bodyError(err)
+			}
+
+			if err := size.add(int64(len(v))); err != nil {
+				return err
+			}
+
+			form.Add(name, string(v))
+
+			continue
+		}
+
+		fds, err := runtime.FieldByPath(md, name)
+		if errors.Is(err, runtime.ErrUnknownField) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		fd := fds[len(fds)-1]
+		if fd.Kind() != protoreflect.BytesKind && !isHTTPBody(fd) {
+			return fmt.Errorf("field '%s' of file part is not bytes or google.api.HttpBody", name)
+		}
+
+		f := &formFile{fds: fds, contentType: p.Header.Get("Content-Type")}
+		files = append(files, f)
+
+		if err := f.read(size.limit(p), mm.TempDir, &memory); err != nil {
+			return bodyError(err)
+		}
+
+		if err := size.add(f.size); err != nil {
+			return err
+		}
+	}
+
+	if err := runtime.PopulateQuery(m, form); err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		if err := f.populate(m.ProtoReflect()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// partsSize limits total size of parts of multipart body.
+type partsSize struct {
+	max   int64 // negative value - no limit
+	total int64
+}
+
+// limit returns reader of part p that ends after one byte over the limit, so exceeding it is detected by add.
+func (s *partsSize) limit(p io.Reader) io.Reader {
+	if s.max < 0 {
+		return p
+	}
+
+	return io.LimitReader(p, s.max-s.total+1)
+}
+
+// add adds size n of read part, it returns 413 error if total size exceeds the limit.
+func (s *partsSize) add(n int64) error {
+	if s.total += n; s.max < 0 || s.total <= s.max {
+		return nil
+	}
+
+	return &HTTPError{
+		HTTPStatus: http.StatusRequestEntityTooLarge,
+		Err:        status.Errorf(codes.ResourceExhausted, "multipart parts are larger than %d bytes", s.max),
+	}
+}
+
+// formFile is file part of multipart body bound to bytes or google.api.HttpBody field.
+type formFile struct {
+	fds         []protoreflect.FieldDescriptor
+	contentType string
+
+	data []byte   // data kept in memory
+	tmp  *os.File // temporary file of data that does not fit in memory
+	size int64    // size of data
+}
+
+// read reads part p to memory while memory budget allows, the rest is written to temporary file in dir.
+func (f *formFile) read(p io.Reader, dir string, memory *int64) error {
+	var buf bytes.Buffer
+
+	if *memory > 0 {
+		n, err := io.CopyN(&buf, p, *memory+1)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		if n <= *memory {
+			*memory -= n
+			f.data = buf.Bytes()
+			f.size = n
+
+			return nil
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, "multipart-")
+	if err != nil {
+		return status.Errorf(codes.Internal, "create temporary file: %v", err)
+	}
+
+	f.tmp = tmp
+
+	n, err := io.Copy(tmp, io.MultiReader(&buf, p))
+	f.size = n
+
+	return err
+}
+
+// populate sets data of the file to its field of message m.
+func (f *formFile) populate(m protoreflect.Message) error {
+	data := f.data
+
+	if f.tmp != nil {
+		var err error
+
+		if data, err = os.ReadFile(f.tmp.Name()); err != nil {
+			return status.Errorf(codes.Internal, "read temporary file: %v", err)
+		}
+	}
+
+	for _, fd := range f.fds[:len(f.fds)-1] {
+		m = m.Mutable(fd).Message()
+	}
+
+	fd := f.fds[len(f.fds)-1]
+
+	switch {
+	case isHTTPBody(fd):
+		m.Set(fd, protoreflect.ValueOfMessage((&httpbody.HttpBody{ContentType: f.contentType, Data: data}).ProtoReflect()))
+	case fd.IsList():
+		m.Mutable(fd).List().Append(protoreflect.ValueOfBytes(data))
+	default:
+		m.Set(fd, protoreflect.ValueOfBytes(data))
+	}
+
+	return nil
+}
+
+// remove removes temporary file of the file part if any.
+func (f *formFile) remove() error {
+	if f.tmp == nil {
+		return nil
+	}
+
+	_ = f.tmp.Close()
+
+	return os.Remove(f.tmp.Name())
+}
+
+// bodyError returns error of reading request body, 413 error if body is too large.
+func bodyError(err error) error {
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return he
+	}
+
+	return err
+}
+
+func responseMarshaler(mar Marshaler) Marshaler {
+	if mar == nil {
+		return DefaultMarshaler
+	}
+
+	return mar
+}
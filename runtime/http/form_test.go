@@ -0,0 +1,248 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	library "google.golang.org/genproto/googleapis/example/library/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type formPart struct {
+	name     string
+	fileName string
+	data     string
+}
+
+// multipartBody returns multipart body of parts and its content type.
+func multipartBody(t *testing.T, parts ...formPart) (string, string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := multipart.NewWriter(&buf)
+
+	for _, p := range parts {
+		h := make(textproto.MIMEHeader)
+		if len(p.fileName) > 0 {
+			h.Set("Content-Disposition", `form-data; name="`+p.name+`"; filename="`+p.fileName+`"`)
+			h.Set("Content-Type", "image/png")
+		} else {
+			h.Set("Content-Disposition", `form-data; name="`+p.name+`"`)
+		}
+
+		pw, err := w.CreatePart(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, _ = pw.Write([]byte(p.data))
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String(), w.FormDataContentType()
+}
+
+func TestDecodeRequest_form(t *testing.T) {
+	tempDir := t.TempDir()
+
+	large := strings.Repeat("a", 1024)
+
+	multipartBook, multipartBookType := multipartBody(t,
+		formPart{"title", "", "Dune"},
+		formPart{"author", "", "Frank Herbert"},
+		formPart{"cover", "cover.png", "ignored"},
+	)
+	multipartFile, multipartFileType := multipartBody(t, formPart{"value", "file.bin", "\x00\x01"})
+	multipartLarge, multipartLargeType := multipartBody(t, formPart{"value", "file.bin", large})
+	multipartFiles, multipartFilesType := multipartBody(t,
+		formPart{"value", "a.bin", large}, formPart{"value", "b.bin", large}, formPart{"value", "c.bin", large},
+	)
+	multipartText, multipartTextType := multipartBody(t,
+		formPart{"title", "", large}, formPart{"author", "", large}, formPart{"name", "", large},
+	)
+	multipartParts, multipartPartsType := multipartBody(t,
+		formPart{"a", "", "1"}, formPart{"b", "", "2"}, formPart{"c", "", "3"}, formPart{"d", "", "4"},
+	)
+	multipartInvalid, multipartInvalidType := multipartBody(t, formPart{"name", "file.bin", "data"})
+
+	tests := []struct {
+		name        string
+		limits      _http.Limits
+		contentType string
+		data        string
+		body        string
+		req         proto.Message
+		want        proto.Message
+		wantCode    int
+	}{
+		{
+			"form",
+			_http.Limits{},
+			"application/x-www-form-urlencoded",
+			"title=Dune&author=Frank+Herbert&unknown=1",
+			"book",
+			&library.UpdateBookRequest{},
+			&library.UpdateBookRequest{Book: &library.Book{Title: "Dune", Author: "Frank Herbert"}},
+			http.StatusOK,
+		},
+		{
+			"form body size",
+			_http.Limits{MaxBodySize: 8},
+			"application/x-www-form-urlencoded",
+			"title=Dune&author=Frank+Herbert",
+			"book",
+			&library.UpdateBookRequest{},
+			nil,
+			http.StatusRequestEntityTooLarge,
+		},
+		{
+			"multipart",
+			_http.Limits{},
+			multipartBookType,
+			multipartBook,
+			"book",
+			&library.UpdateBookRequest{},
+			&library.UpdateBookRequest{Book: &library.Book{Title: "Dune", Author: "Frank Herbert"}},
+			http.StatusOK,
+		},
+		{
+			"multipart file",
+			_http.Limits{},
+			multipartFileType,
+			multipartFile,
+			"*",
+			&wrapperspb.BytesValue{},
+			&wrapperspb.BytesValue{Value: []byte("\x00\x01")},
+			http.StatusOK,
+		},
+		{
+			"multipart temporary file",
+			_http.Limits{},
+			multipartLargeType,
+			multipartLarge,
+			"*",
+			&wrapperspb.BytesValue{},
+			&wrapperspb.BytesValue{Value: []byte(large)},
+			http.StatusOK,
+		},
+		{
+			"multipart body size",
+			_http.Limits{MaxBodySize: 512},
+			multipartLargeType,
+			multipartLarge,
+			"*",
+			&wrapperspb.BytesValue{},
+			nil,
+			http.StatusRequestEntityTooLarge,
+		},
+		{
+			"multipart parts size",
+			_http.Limits{},
+			multipartFilesType,
+			multipartFiles,
+			"*",
+			&wrapperspb.BytesValue{},
+			nil,
+			http.StatusRequestEntityTooLarge,
+		},
+		{
+			"multipart text size",
+			_http.Limits{},
+			multipartTextType,
+			multipartText,
+			"book",
+			&library.UpdateBookRequest{},
+			nil,
+			http.StatusRequestEntityTooLarge,
+		},
+		{
+			"multipart parts",
+			_http.Limits{},
+			multipartPartsType,
+			multipartParts,
+			"book",
+			&library.UpdateBookRequest{},
+			nil,
+			http.StatusBadRequest,
+		},
+		{
+			"multipart file of string field",
+			_http.Limits{},
+			multipartInvalidType,
+			multipartInvalid,
+			"book",
+			&library.UpdateBookRequest{},
+			nil,
+			http.StatusBadRequest,
+		},
+		{
+			"multipart without boundary",
+			_http.Limits{},
+			"multipart/form-data",
+			multipartFile,
+			"*",
+			&wrapperspb.BytesValue{},
+			nil,
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := _http.NewMap()
+			m.UseLimits(tt.limits)
+			m.RegisterMarshaler(_http.FormContentType, &_http.FormMarshaler{})
+			m.RegisterMarshaler(_http.MultipartContentType, &_http.MultipartMarshaler{MaxMemory: 512, TempDir: tempDir, MaxParts: 3, MaxPartsSize: 2048})
+
+			req := tt.req
+
+			if err := m.Add("POST", "/v1/books", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				if err := _http.DecodeRequest(ctx, r, req, tt.body); err != nil {
+					_http.WriteError(ctx, w, r, err)
+
+					return
+				}
+
+				_, _ = w.Write([]byte(`{}`))
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/v1/books", strings.NewReader(tt.data))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			m.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("ServeHTTP() code = %v, want %v: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+
+			if tt.want != nil && !proto.Equal(req, tt.want) {
+				t.Errorf("DecodeRequest() = %v, want %v", req, tt.want)
+			}
+
+			files, err := os.ReadDir(tempDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(files) > 0 {
+				t.Errorf("DecodeRequest() temporary files are not removed: %v", files)
+			}
+		})
+	}
+}
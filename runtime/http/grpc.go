@@ -0,0 +1,46 @@
+package http
+
+import "google.golang.org/grpc"
+
+// GRPCInterceptors is interceptor configuration shared by gRPC server and REST routes,
+// so RPCs called in-process through REST routes run the same interceptor chain as native gRPC calls.
+type GRPCInterceptors struct {
+	Unary  []grpc.UnaryServerInterceptor
+	Stream []grpc.StreamServerInterceptor
+}
+
+// ServerOptions returns gRPC server options that install the interceptors.
+func (c GRPCInterceptors) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(c.Unary...),
+		grpc.ChainStreamInterceptor(c.Stream...),
+	}
+}
+
+// RouteOption returns route option that installs the interceptors.
+func (c GRPCInterceptors) RouteOption() RouteOption {
+	return WithGRPCInterceptors(c.Unary, c.Stream)
+}
+
+// WithGRPCUnaryInterceptors adds gRPC unary interceptors to the route.
+func WithGRPCUnaryInterceptors(ii ...grpc.UnaryServerInterceptor) RouteOption {
+	return WithGRPCInterceptors(ii, nil)
+}
+
+// WithGRPCStreamInterceptors adds gRPC stream interceptors to the route.
+func WithGRPCStreamInterceptors(ii ...grpc.StreamServerInterceptor) RouteOption {
+	return WithGRPCInterceptors(nil, ii)
+}
+
+// WithGRPCInterceptors adds gRPC unary and stream interceptors to the route.
+func WithGRPCInterceptors(unary []grpc.UnaryServerInterceptor, stream []grpc.StreamServerInterceptor) RouteOption {
+	return func(r *Route) {
+		for _, i := range unary {
+			r.interceptors.Unary = append(r.interceptors.Unary, UnaryServerInterceptorFromGRPC(i))
+		}
+
+		for _, i := range stream {
+			r.interceptors.Stream = append(r.interceptors.Stream, StreamServerInterceptorFromGRPC(i))
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/grpc"
+)
+
+func TestGRPCInterceptors_RouteOption(t *testing.T) {
+	var got []string
+
+	srv := &struct{}{}
+
+	c := _http.GRPCInterceptors{
+		Unary: []grpc.UnaryServerInterceptor{
+			func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				if info.Server != srv {
+					t.Errorf("UnaryServerInfo.Server = %v, want %v", info.Server, srv)
+				}
+
+				got = append(got, "auth:"+info.FullMethod)
+
+				return handler(ctx, req)
+			},
+			func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+				got = append(got, "tracing:"+info.FullMethod)
+
+				return handler(ctx, req)
+			},
+		},
+	}
+
+	if l := len(c.ServerOptions()); l != 2 {
+		t.Errorf("GRPCInterceptors.ServerOptions() len = %v, want 2", l)
+	}
+
+	m := _http.NewMap()
+	if err := m.Add("GET", "/v1/articles/{value}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		_, _ = _http.Invoke(ctx, srv, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+			got = append(got, "handler")
+
+			return nil, nil
+		})
+	}, _http.WithRPC("example.Articles", "GetArticle"), c.RouteOption()); err != nil {
+		t.Fatal(err)
+	}
+
+	m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/articles/12345", nil))
+
+	want := []string{
+		"auth:/example.Articles/GetArticle",
+		"tracing:/example.Articles/GetArticle",
+		"handler",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Invoke() calls = %v, want %v", got, want)
+	}
+}
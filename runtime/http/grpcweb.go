@@ -0,0 +1,185 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// GRPCWebContentType is content type of gRPC-Web requests with binary messages.
+	GRPCWebContentType = "application/grpc-web"
+
+	// GRPCWebTextContentType is content type of gRPC-Web requests with base64 encoded messages.
+	GRPCWebTextContentType = "application/grpc-web-text"
+)
+
+// grpcWebTrailerFlag marks gRPC-Web frame of trailers.
+const grpcWebTrailerFlag = 0x80
+
+// IsGRPCWebRequest reports whether r is gRPC-Web request, i.e. POST request of "application/grpc-web"
+// or "application/grpc-web-text" content type, optionally followed by "+<codec>".
+func IsGRPCWebRequest(r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		return false
+	}
+
+	t := mediaType(r)
+
+	for _, ct := range []string{GRPCWebContentType, GRPCWebTextContentType} {
+		if t == ct || strings.HasPrefix(t, ct+"+") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// serveGRPCWeb serves gRPC-Web request with gRPC server gs. Request is converted to native gRPC request,
+// messages of response are written as is, trailers are written as the last frame of response body.
+// Request body is limited by l.
+func serveGRPCWeb(gs http.Handler, w http.ResponseWriter, r *http.Request, l Limits) {
+	ww := newGRPCWebResponseWriter(w, r)
+
+	limited, err := l.limitBody(r)
+	if err != nil {
+		ww.writeStatus(status.Convert(err))
+
+		return
+	}
+
+	body := io.Reader(limited)
+	if ww.text {
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+
+	// "application/grpc-web+proto" -> "application/grpc+proto"
+	ct := GRPCContentType + strings.TrimPrefix(strings.TrimPrefix(ww.contentType, GRPCWebTextContentType), GRPCWebContentType)
+
+	gs.ServeHTTP(ww, grpcRequest(r, ct, body))
+	ww.writeTrailers()
+}
+
+// grpcWebResponseWriter converts response of gRPC server to gRPC-Web response.
+type grpcWebResponseWriter struct {
+	w           http.ResponseWriter
+	header      http.Header // headers and trailers set by gRPC server
+	contentType string
+	text        bool
+	wroteHeader bool
+}
+
+// newGRPCWebResponseWriter returns writer of response of gRPC-Web request r in its content type.
+func newGRPCWebResponseWriter(w http.ResponseWriter, r *http.Request) *grpcWebResponseWriter {
+	t := mediaType(r)
+
+	return &grpcWebResponseWriter{
+		w:           w,
+		header:      make(http.Header),
+		contentType: t,
+		text:        strings.HasPrefix(t, GRPCWebTextContentType),
+	}
+}
+
+func (ww *grpcWebResponseWriter) Header() http.Header {
+	return ww.header
+}
+
+func (ww *grpcWebResponseWriter) WriteHeader(code int) {
+	if ww.wroteHeader {
+		return
+	}
+
+	ww.wroteHeader = true
+
+	h := ww.w.Header()
+
+	for k, vv := range ww.header {
+		if k == "Trailer" || k == "Content-Type" || isGRPCTrailer(k) {
+			continue
+		}
+
+		h[k] = vv
+	}
+
+	h.Set("Content-Type", ww.contentType)
+	ww.w.WriteHeader(code)
+}
+
+func (ww *grpcWebResponseWriter) Write(p []byte) (int, error) {
+	ww.WriteHeader(http.StatusOK)
+
+	if !ww.text {
+		return ww.w.Write(p)
+	}
+
+	if _, err := io.WriteString(ww.w, base64.StdEncoding.EncodeToString(p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+func (ww *grpcWebResponseWriter) Flush() {
+	ww.WriteHeader(http.StatusOK)
+
+	if f, ok := ww.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// writeTrailers writes trailers set by gRPC server as gRPC-Web trailer frame.
+func (ww *grpcWebResponseWriter) writeTrailers() {
+	var tt []string
+
+	for k, vv := range ww.header {
+		if !isGRPCTrailer(k) {
+			continue
+		}
+
+		k = strings.ToLower(strings.TrimPrefix(k, http.TrailerPrefix))
+		for _, v := range vv {
+			tt = append(tt, k+": "+v+"\r\n")
+		}
+	}
+
+	sort.Strings(tt)
+
+	payload := strings.Join(tt, "")
+
+	frame := make([]byte, 5, 5+len(payload))
+	frame[0] = grpcWebTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(payload)))
+	frame = append(frame, payload...)
+
+	_, _ = ww.Write(frame)
+	ww.Flush()
+}
+
+// writeStatus writes status s as trailers of response without messages.
+func (ww *grpcWebResponseWriter) writeStatus(s *status.Status) {
+	ww.header.Set("Grpc-Status", strconv.Itoa(int(s.Code())))
+	ww.header.Set("Grpc-Message", s.Message())
+	ww.writeTrailers()
+}
+
+// writeGRPCWebError writes error status s of gRPC-Web request r as response without messages.
+func writeGRPCWebError(w http.ResponseWriter, r *http.Request, s *status.Status) {
+	newGRPCWebResponseWriter(w, r).writeStatus(s)
+}
+
+// isGRPCTrailer reports whether header k set by gRPC server is trailer.
+func isGRPCTrailer(k string) bool {
+	switch k {
+	case "Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin":
+		return true
+	default:
+		return strings.HasPrefix(k, http.TrailerPrefix)
+	}
+}
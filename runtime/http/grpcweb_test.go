@@ -0,0 +1,277 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGRPCHandler_gRPCWeb(t *testing.T) {
+	gs := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(gs, health.NewServer())
+
+	m := _http.NewMap()
+	h := _http.GRPCHandler(gs, m)
+
+	serving, err := proto.Marshal(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		req         *grpc_health_v1.HealthCheckRequest
+		want        []byte
+	}{
+		{
+			"binary",
+			"application/grpc-web+proto",
+			&grpc_health_v1.HealthCheckRequest{},
+			append(frame(0, serving), frame(0x80, []byte("grpc-status: 0\r\n"))...),
+		},
+		{
+			"binary without codec",
+			"application/grpc-web",
+			&grpc_health_v1.HealthCheckRequest{},
+			append(frame(0, serving), frame(0x80, []byte("grpc-status: 0\r\n"))...),
+		},
+		{
+			"text",
+			"application/grpc-web-text",
+			&grpc_health_v1.HealthCheckRequest{},
+			append(frame(0, serving), frame(0x80, []byte("grpc-status: 0\r\n"))...),
+		},
+		{
+			"error",
+			"application/grpc-web+proto",
+			&grpc_health_v1.HealthCheckRequest{Service: "unknown"},
+			frame(0x80, []byte("grpc-message: unknown service\r\ngrpc-status: 5\r\n")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := proto.Marshal(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body := frame(0, data)
+
+			text := tt.contentType == "application/grpc-web-text"
+			if text {
+				body = []byte(base64.StdEncoding.EncodeToString(body))
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/grpc.health.v1.Health/Check", bytes.NewReader(body))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("ServeHTTP() code = %v, want 200", w.Code)
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Errorf("ServeHTTP() Content-Type = %v, want %v", ct, tt.contentType)
+			}
+
+			got := w.Body.Bytes()
+			if text {
+				// every write is encoded separately
+				got = decodeBase64Chunks(t, w.Body.String())
+			}
+
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("ServeHTTP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGRPCHandler_gRPCWebCORS(t *testing.T) {
+	gs := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(gs, health.NewServer())
+
+	m := _http.NewMap()
+	m.UseCORS(_http.CORS{AllowedOrigins: []string{"https://app.example.com"}})
+
+	h := _http.GRPCHandler(gs, m)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		origin     string
+		reqHeaders string
+		wantCode   int
+		wantHeader http.Header
+	}{
+		{
+			"preflight",
+			"OPTIONS",
+			"/grpc.health.v1.Health/Check",
+			"https://app.example.com",
+			"content-type, x-grpc-web, x-user-agent, grpc-timeout",
+			http.StatusNoContent,
+			http.Header{
+				"Access-Control-Allow-Origin":  {"https://app.example.com"},
+				"Access-Control-Allow-Methods": {"POST"},
+				"Access-Control-Allow-Headers": {"content-type, x-grpc-web, x-user-agent, grpc-timeout"},
+			},
+		},
+		{
+			"preflight of not allowed origin",
+			"OPTIONS",
+			"/grpc.health.v1.Health/Check",
+			"https://evil.example.com",
+			"content-type, x-grpc-web",
+			http.StatusNoContent,
+			http.Header{"Access-Control-Allow-Origin": nil, "Access-Control-Allow-Methods": nil},
+		},
+		{
+			"preflight of unknown method",
+			"OPTIONS",
+			"/grpc.health.v1.Health/Unknown",
+			"https://app.example.com",
+			"content-type",
+			http.StatusNotFound,
+			http.Header{"Access-Control-Allow-Methods": nil},
+		},
+		{
+			"request",
+			"POST",
+			"/grpc.health.v1.Health/Check",
+			"https://app.example.com",
+			"",
+			http.StatusOK,
+			http.Header{
+				"Access-Control-Allow-Origin":   {"https://app.example.com"},
+				"Access-Control-Expose-Headers": {"Grpc-Status, Grpc-Message, Grpc-Status-Details-Bin"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.path, bytes.NewReader(frame(0, nil)))
+			r.Header.Set("Origin", tt.origin)
+
+			if tt.method == "OPTIONS" {
+				r.Header.Set("Access-Control-Request-Method", "POST")
+				r.Header.Set("Access-Control-Request-Headers", tt.reqHeaders)
+			} else {
+				r.Header.Set("Content-Type", "application/grpc-web+proto")
+			}
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("ServeHTTP() code = %v, want %v", w.Code, tt.wantCode)
+			}
+
+			for k, want := range tt.wantHeader {
+				if got := w.Header().Values(k); !reflect.DeepEqual(got, want) && (len(got) > 0 || len(want) > 0) {
+					t.Errorf("ServeHTTP() %s = %v, want %v", k, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGRPCHandler_gRPCWebLimits(t *testing.T) {
+	gs := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(gs, health.NewServer())
+
+	data, err := proto.Marshal(&grpc_health_v1.HealthCheckRequest{Service: "a-long-service-name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		route []_http.RouteOption // options of REST route of the method, nil if there is no route
+		want  string
+	}{
+		{"map limit", nil, "grpc-status: 8\r\n"},
+		{
+			"route limit",
+			[]_http.RouteOption{_http.WithRPC("grpc.health.v1.Health", "Check"), _http.WithLimits(_http.Limits{MaxBodySize: 1024})},
+			"grpc-status: 5\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := _http.NewMap()
+			m.UseLimits(_http.Limits{MaxBodySize: 8})
+
+			if tt.route != nil {
+				if err := m.Add("POST", "/v1/health:check", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {}, tt.route...); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/grpc.health.v1.Health/Check", bytes.NewReader(frame(0, data)))
+			r.Header.Set("Content-Type", "application/grpc-web+proto")
+
+			_http.GRPCHandler(gs, m).ServeHTTP(w, r)
+
+			if got := w.Body.String(); !strings.Contains(got, tt.want) {
+				t.Errorf("ServeHTTP() = %q, want %q in it", got, tt.want)
+			}
+		})
+	}
+}
+
+// frame returns gRPC frame of data with flag.
+func frame(flag byte, data []byte) []byte {
+	f := make([]byte, 5, 5+len(data))
+	f[0] = flag
+	binary.BigEndian.PutUint32(f[1:], uint32(len(data)))
+
+	return append(f, data...)
+}
+
+// decodeBase64Chunks decodes concatenated padded base64 strings.
+func decodeBase64Chunks(t *testing.T, s string) []byte {
+	t.Helper()
+
+	var data []byte
+
+	for len(s) > 0 {
+		n := len(s)
+		for i := 0; i+4 <= len(s); i += 4 {
+			if s[i+3] == '=' {
+				n = i + 4
+
+				break
+			}
+		}
+
+		b, err := base64.StdEncoding.DecodeString(s[:n])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, s = append(data, b...), s[n:]
+	}
+
+	return data
+}
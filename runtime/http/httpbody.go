@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/amsokol/protobuf-rest/runtime"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// HTTPBodyContentType is content type of google.api.HttpBody response without content type.
+const HTTPBodyContentType = "application/octet-stream"
+
+// isHTTPBody reports whether fd is singular google.api.HttpBody field.
+func isHTTPBody(fd protoreflect.FieldDescriptor) bool {
+	return fd.Message() != nil && !fd.IsList() && !fd.IsMap() && fd.Message().FullName() == "google.api.HttpBody"
+}
+
+// isHTTPBodyBinding reports whether request body is bound to google.api.HttpBody of request message.
+func isHTTPBodyBinding(req proto.Message, body string) bool {
+	if body == "*" {
+		_, ok := req.(*httpbody.HttpBody)
+
+		return ok
+	}
+
+	fds, err := runtime.FieldByPath(req.ProtoReflect().Descriptor(), body)
+
+	return err == nil && isHTTPBody(fds[len(fds)-1])
+}
+
+// requestHTTPBody returns google.api.HttpBody of request message bound to request body,
+// nil if request body is not bound to google.api.HttpBody.
+func requestHTTPBody(req proto.Message, body string) *httpbody.HttpBody {
+	if body == "*" {
+		hb, _ := req.(*httpbody.HttpBody)
+
+		return hb
+	}
+
+	fds, err := runtime.FieldByPath(req.ProtoReflect().Descriptor(), body)
+	if err != nil || !isHTTPBody(fds[len(fds)-1]) {
+		return nil
+	}
+
+	m := req.ProtoReflect()
+	for _, fd := range fds {
+		m = m.Mutable(fd).Message()
+	}
+
+	hb, _ := m.Interface().(*httpbody.HttpBody)
+
+	return hb
+}
+
+// responseHTTPBody returns google.api.HttpBody of response message written to response body,
+// nil if response body is not google.api.HttpBody.
+func responseHTTPBody(resp proto.Message, responseBody string) (*httpbody.HttpBody, bool) {
+	if len(responseBody) == 0 {
+		hb, ok := resp.(*httpbody.HttpBody)
+
+		return hb, ok
+	}
+
+	fds, err := runtime.FieldByPath(resp.ProtoReflect().Descriptor(), responseBody)
+	if err != nil || !isHTTPBody(fds[len(fds)-1]) {
+		return nil, false
+	}
+
+	m := resp.ProtoReflect()
+	for _, fd := range fds {
+		m = m.Get(fd).Message()
+	}
+
+	hb, ok := m.Interface().(*httpbody.HttpBody)
+
+	return hb, ok
+}
+
+// writeHTTPBodyHeader writes headers of google.api.HttpBody response with content type of hb.
+func writeHTTPBodyHeader(ctx context.Context, w http.ResponseWriter, hb *httpbody.HttpBody) {
+	code := writeMetadata(ctx, w)
+	if code == 0 {
+		code = http.StatusOK
+	}
+
+	ct := hb.GetContentType()
+	if len(ct) == 0 {
+		ct = HTTPBodyContentType
+	}
+
+	w.Header().Set("Content-Type", ct)
+	w.WriteHeader(code)
+}
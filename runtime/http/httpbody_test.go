@@ -0,0 +1,75 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDecodeRequest_HttpBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        string
+		want        *httpbody.HttpBody
+	}{
+		{"binary", "image/png", "\x89PNG\x00", &httpbody.HttpBody{ContentType: "image/png", Data: []byte("\x89PNG\x00")}},
+		{"not JSON", "application/json", "{", &httpbody.HttpBody{ContentType: "application/json", Data: []byte("{")}},
+		{"no body", "text/plain", "", &httpbody.HttpBody{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/v1/images", strings.NewReader(tt.data))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			got := &httpbody.HttpBody{}
+			if err := _http.DecodeRequest(context.Background(), r, got, "*"); err != nil {
+				t.Fatalf("DecodeRequest() error = %v", err)
+			}
+
+			if !proto.Equal(got, tt.want) {
+				t.Errorf("DecodeRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteResponse_HttpBody(t *testing.T) {
+	tests := []struct {
+		name            string
+		resp            *httpbody.HttpBody
+		wantContentType string
+	}{
+		{"content type", &httpbody.HttpBody{ContentType: "image/png", Data: []byte("\x89PNG\x00")}, "image/png"},
+		{"default content type", &httpbody.HttpBody{Data: []byte("data")}, "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/v1/images/1", nil)
+			r.Header.Set("Accept", "application/json")
+
+			_http.WriteResponse(context.Background(), w, r, tt.resp, "")
+
+			if w.Code != http.StatusOK {
+				t.Errorf("WriteResponse() code = %v, want 200", w.Code)
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != tt.wantContentType {
+				t.Errorf("WriteResponse() Content-Type = %v, want %v", ct, tt.wantContentType)
+			}
+
+			if got := w.Body.String(); got != string(tt.resp.GetData()) {
+				t.Errorf("WriteResponse() = %q, want %q", got, tt.resp.GetData())
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package http
+
+import (
+	"context"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInfo consists of various information about unary RPC called through REST route.
+type UnaryServerInfo struct {
+	Server     interface{} // service implementation
+	FullMethod string      // full RPC method string, i.e., /package.service/method
+	Route      *Route      // matched route, nil if RPC is called outside of Map
+}
+
+// UnaryHandler defines the handler invoked by UnaryServerInterceptor to complete the normal
+// execution of unary RPC.
+type UnaryHandler func(ctx context.Context, req interface{}) (interface{}, error)
+
+// UnaryServerInterceptor provides a hook to intercept the execution of unary RPC called
+// through REST route. It mirrors grpc.UnaryServerInterceptor.
+type UnaryServerInterceptor func(ctx context.Context, req interface{}, info *UnaryServerInfo, handler UnaryHandler) (interface{}, error)
+
+// StreamServerInfo consists of various information about streaming RPC called through REST route.
+type StreamServerInfo struct {
+	FullMethod     string // full RPC method string, i.e., /package.service/method
+	IsClientStream bool   // indicates whether the RPC is a client streaming RPC
+	IsServerStream bool   // indicates whether the RPC is a server streaming RPC
+	Route          *Route // matched route, nil if RPC is called outside of Map
+}
+
+// StreamHandler defines the handler invoked by StreamServerInterceptor to complete the normal
+// execution of streaming RPC.
+type StreamHandler func(srv interface{}, stream grpc.ServerStream) error
+
+// StreamServerInterceptor provides a hook to intercept the execution of streaming RPC called
+// through REST route. It mirrors grpc.StreamServerInterceptor.
+type StreamServerInterceptor func(srv interface{}, ss grpc.ServerStream, info *StreamServerInfo, handler StreamHandler) error
+
+// Interceptors is set of unary and stream interceptors.
+type Interceptors struct {
+	Unary  []UnaryServerInterceptor
+	Stream []StreamServerInterceptor
+}
+
+// UnaryServerInterceptorFromGRPC adapts gRPC unary server interceptor to run on REST routes.
+func UnaryServerInterceptorFromGRPC(i grpc.UnaryServerInterceptor) UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *UnaryServerInfo, handler UnaryHandler) (interface{}, error) {
+		return i(ctx, req, &grpc.UnaryServerInfo{Server: info.Server, FullMethod: info.FullMethod}, grpc.UnaryHandler(handler))
+	}
+}
+
+// StreamServerInterceptorFromGRPC adapts gRPC stream server interceptor to run on REST routes.
+func StreamServerInterceptorFromGRPC(i grpc.StreamServerInterceptor) StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *StreamServerInfo, handler StreamHandler) error {
+		return i(srv, ss, &grpc.StreamServerInfo{
+			FullMethod:     info.FullMethod,
+			IsClientStream: info.IsClientStream,
+			IsServerStream: info.IsServerStream,
+		}, grpc.StreamHandler(handler))
+	}
+}
+
+// WithUnaryInterceptors adds unary interceptors to the route.
+// Route interceptors are called after Map and service interceptors.
+func WithUnaryInterceptors(ii ...UnaryServerInterceptor) RouteOption {
+	return func(r *Route) {
+		r.interceptors.Unary = append(r.interceptors.Unary, ii...)
+	}
+}
+
+// WithStreamInterceptors adds stream interceptors to the route.
+// Route interceptors are called after Map and service interceptors.
+func WithStreamInterceptors(ii ...StreamServerInterceptor) RouteOption {
+	return func(r *Route) {
+		r.interceptors.Stream = append(r.interceptors.Stream, ii...)
+	}
+}
+
+// Invoke calls unary RPC handler through interceptors of the route from the context.
+// It is used by generated code after request message is decoded.
+// If the context has deadline, e.g. of request timeout, and it is exceeded before the handler returns,
+// the context error is returned as gRPC status without waiting for the handler, so services that
+// ignore the context do not delay the response. The handler keeps running until it returns,
+// its later panic is reported to panic hooks of the Map.
+func Invoke(ctx context.Context, srv interface{}, req interface{}, handler UnaryHandler) (interface{}, error) {
+	r := RouteFromContext(ctx)
+
+	ii := r.unaryInterceptors()
+	if len(ii) > 0 {
+		info := &UnaryServerInfo{
+			Server:     srv,
+			FullMethod: r.FullMethod(),
+			Route:      r,
+		}
+
+		handler = chainUnary(ii, info, handler)
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		return handler(ctx, req)
+	}
+
+	type result struct {
+		out   interface{}
+		err   error
+		panic *handlerPanic
+	}
+
+	done := make(chan result)
+	gone := make(chan struct{}) // closed when the caller does not wait for the result
+
+	go func() {
+		var res result
+
+		defer func() {
+			if p := recover(); p != nil {
+				// stack of this goroutine has the panic site
+				res.panic = &handlerPanic{value: p, stack: debug.Stack()}
+			}
+
+			select {
+			case done <- res:
+			case <-gone:
+				if res.panic != nil {
+					reportLatePanic(ctx, res.panic.value, res.panic.stack)
+				}
+			}
+		}()
+
+		res.out, res.err = handler(ctx, req)
+	}()
+
+	select {
+	case res := <-done:
+		if res.panic != nil {
+			// recovered by the Map in goroutine of the request
+			panic(res.panic)
+		}
+
+		if cerr := ctx.Err(); cerr != nil {
+			return nil, status.FromContextError(cerr).Err()
+		}
+
+		return res.out, res.err
+	case <-ctx.Done():
+		close(gone)
+
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// InvokeStream calls streaming RPC handler through interceptors of the route from the stream context.
+// It is used by generated code.
+func InvokeStream(srv interface{}, ss grpc.ServerStream, isClientStream, isServerStream bool, handler StreamHandler) error {
+	r := RouteFromContext(ss.Context())
+
+	ii := r.streamInterceptors()
+	if len(ii) == 0 {
+		return handler(srv, ss)
+	}
+
+	info := &StreamServerInfo{
+		FullMethod:     r.FullMethod(),
+		IsClientStream: isClientStream,
+		IsServerStream: isServerStream,
+		Route:          r,
+	}
+
+	return chainStream(ii, info, handler)(srv, ss)
+}
+
+func chainUnary(ii []UnaryServerInterceptor, info *UnaryServerInfo, handler UnaryHandler) UnaryHandler {
+	for i := len(ii) - 1; i >= 0; i-- {
+		next, interceptor := handler, ii[i]
+		handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+			return interceptor(ctx, req, info, next)
+		}
+	}
+
+	return handler
+}
+
+func chainStream(ii []StreamServerInterceptor, info *StreamServerInfo, handler StreamHandler) StreamHandler {
+	for i := len(ii) - 1; i >= 0; i-- {
+		next, interceptor := handler, ii[i]
+		handler = func(srv interface{}, ss grpc.ServerStream) error {
+			return interceptor(srv, ss, info, next)
+		}
+	}
+
+	return handler
+}
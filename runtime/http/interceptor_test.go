@@ -0,0 +1,124 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/grpc"
+)
+
+func TestInvoke(t *testing.T) {
+	var got []string
+
+	interceptor := func(name string) _http.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *_http.UnaryServerInfo, handler _http.UnaryHandler) (interface{}, error) {
+			got = append(got, name+":"+info.FullMethod)
+
+			return handler(ctx, req)
+		}
+	}
+
+	m := _http.NewMap()
+	m.UseUnary(interceptor("map"))
+	m.UseUnary(_http.UnaryServerInterceptorFromGRPC(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			got = append(got, "grpc:"+info.FullMethod)
+
+			return handler(ctx, req)
+		}))
+	m.UseServiceUnary("example.Articles", interceptor("service"))
+	m.UseServiceUnary("example.Books", interceptor("other service"))
+
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		resp, err := _http.Invoke(ctx, nil, "request", func(ctx context.Context, req interface{}) (interface{}, error) {
+			got = append(got, "handler:"+req.(string))
+
+			return "response", nil
+		})
+		if err != nil || resp != "response" {
+			t.Errorf("Invoke() = %v, %v, want response", resp, err)
+		}
+	}
+
+	if err := m.Add("GET", "/v1/articles/{value}", h,
+		_http.WithRPC("example.Articles", "GetArticle"), _http.WithUnaryInterceptors(interceptor("route"))); err != nil {
+		t.Fatal(err)
+	}
+
+	m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/articles/12345", nil))
+
+	want := []string{
+		"map:/example.Articles/GetArticle",
+		"grpc:/example.Articles/GetArticle",
+		"service:/example.Articles/GetArticle",
+		"route:/example.Articles/GetArticle",
+		"handler:request",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Invoke() calls = %v, want %v", got, want)
+	}
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestInvokeStream(t *testing.T) {
+	var got []string
+
+	m := _http.NewMap()
+	m.UseStream(_http.StreamServerInterceptorFromGRPC(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if !info.IsServerStream || info.IsClientStream {
+				t.Errorf("StreamServerInfo = %+v, want server stream", info)
+			}
+
+			got = append(got, "grpc:"+info.FullMethod)
+
+			return handler(srv, ss)
+		}))
+
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		ss := &testServerStream{ctx: ctx}
+
+		if err := _http.InvokeStream(nil, ss, false, true, func(srv interface{}, stream grpc.ServerStream) error {
+			got = append(got, "handler")
+
+			return nil
+		}); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if err := m.Add("GET", "/v1/articles:stream", h,
+		_http.WithRPC("example.Articles", "StreamArticles"),
+		_http.WithStreamInterceptors(func(srv interface{}, ss grpc.ServerStream, info *_http.StreamServerInfo, handler _http.StreamHandler) error {
+			got = append(got, "route:"+info.Route.Path.String())
+
+			return handler(srv, ss)
+		})); err != nil {
+		t.Fatal(err)
+	}
+
+	m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/articles:stream", nil))
+
+	want := []string{
+		"grpc:/example.Articles/StreamArticles",
+		"route:/v1/articles:stream",
+		"handler",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("InvokeStream() calls = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Limits restrict decoding of HTTP request body. Zero field means default limit, negative field - no limit.
+type Limits struct {
+	MaxBodySize int64 // maximum size of request body in bytes, exceeding it responds 413 Request Entity Too Large
+	MaxDepth    int   // maximum nesting depth of JSON objects and arrays, exceeding it responds 400 Bad Request
+	MaxRepeated int   // maximum number of elements of JSON array, exceeding it responds 400 Bad Request
+}
+
+// DefaultLimits are used for limits that are not set by the Map and the route.
+// Default body size is the same as default maximum message size of gRPC server.
+var DefaultLimits = Limits{
+	MaxBodySize: 4 << 20,
+	MaxDepth:    100,
+	MaxRepeated: -1,
+}
+
+// UseLimits sets limits of request body decoding of all routes of the Map.
+// Limits of the route set with WithLimits take precedence.
+func (m *Map) UseLimits(l Limits) {
+	m.limits = l
+}
+
+// WithLimits sets limits of request body decoding of the route, e.g. to allow large uploads.
+// Zero fields are inherited from the Map.
+func WithLimits(l Limits) RouteOption {
+	return func(r *Route) {
+		r.limits = l
+	}
+}
+
+// Limits returns limits of request body decoding of the route.
+func (r *Route) Limits() Limits {
+	l := DefaultLimits
+
+	if r == nil {
+		return l
+	}
+
+	if r.m != nil {
+		l = l.override(r.m.limits)
+	}
+
+	return l.override(r.limits)
+}
+
+// override returns limits with non-zero fields of o.
+func (l Limits) override(o Limits) Limits {
+	if o.MaxBodySize != 0 {
+		l.MaxBodySize = o.MaxBodySize
+	}
+
+	if o.MaxDepth != 0 {
+		l.MaxDepth = o.MaxDepth
+	}
+
+	if o.MaxRepeated != 0 {
+		l.MaxRepeated = o.MaxRepeated
+	}
+
+	return l
+}
+
+// readBody reads request body up to l.MaxBodySize.
+func (l Limits) readBody(r *http.Request) ([]byte, error) {
+	if l.MaxBodySize < 0 {
+		return io.ReadAll(r.Body)
+	}
+
+	if r.ContentLength > l.MaxBodySize {
+		return nil, l.errBodySize()
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, l.MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > l.MaxBodySize {
+		return nil, l.errBodySize()
+	}
+
+	return data, nil
+}
+
+// limitBody returns request body that fails with 413 error after l.MaxBodySize bytes.
+func (l Limits) limitBody(r *http.Request) (io.Reader, error) {
+	if l.MaxBodySize < 0 {
+		return r.Body, nil
+	}
+
+	if r.ContentLength > l.MaxBodySize {
+		return nil, l.errBodySize()
+	}
+
+	return &limitedBody{r: io.LimitReader(r.Body, l.MaxBodySize+1), l: l}, nil
+}
+
+type limitedBody struct {
+	r    io.Reader
+	l    Limits
+	read int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.read > b.l.MaxBodySize {
+		return 0, b.l.errBodySize()
+	}
+
+	n, err := b.r.Read(p)
+
+	b.read += int64(n)
+	if b.read > b.l.MaxBodySize {
+		return n - int(b.read-b.l.MaxBodySize), b.l.errBodySize()
+	}
+
+	return n, err
+}
+
+func (l Limits) errBodySize() error {
+	return &HTTPError{
+		HTTPStatus: http.StatusRequestEntityTooLarge,
+		Err:        status.Errorf(codes.ResourceExhausted, "request body is larger than %d bytes", l.MaxBodySize),
+	}
+}
+
+// checkJSON checks nesting depth and array lengths of JSON data.
+func (l Limits) checkJSON(data []byte) error {
+	if l.MaxDepth < 0 && l.MaxRepeated < 0 {
+		return nil
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+
+	// number of elements of arrays, -1 for objects
+	var stack []int
+
+	for {
+		t, err := d.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			// syntax errors are reported by unmarshaler
+			return nil
+		}
+
+		if n := len(stack); n > 0 && stack[n-1] >= 0 && t != json.Delim(']') {
+			stack[n-1]++
+
+			if l.MaxRepeated >= 0 && stack[n-1] > l.MaxRepeated {
+				return status.Errorf(codes.InvalidArgument, "JSON array has more than %d elements", l.MaxRepeated)
+			}
+		}
+
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			c := -1
+			if t == json.Delim('[') {
+				c = 0
+			}
+
+			stack = append(stack, c)
+
+			if l.MaxDepth >= 0 && len(stack) > l.MaxDepth {
+				return status.Errorf(codes.InvalidArgument, "JSON nesting depth is more than %d", l.MaxDepth)
+			}
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// HTTPError is error with HTTP status code that overrides status code converted from gRPC code of Err.
+type HTTPError struct {
+	HTTPStatus int
+	Err        error
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%d %s: %v", e.HTTPStatus, http.StatusText(e.HTTPStatus), e.Err)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// GRPCStatus returns gRPC status of Err, so status.Convert and status.Code work for HTTPError.
+func (e *HTTPError) GRPCStatus() *status.Status {
+	return status.Convert(e.Err)
+}
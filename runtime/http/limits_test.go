@@ -0,0 +1,132 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/genproto/googleapis/api/annotations"
+)
+
+func TestMap_UseLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   _http.Limits
+		route    []_http.RouteOption
+		body     string
+		wantCode int
+		want     string
+	}{
+		{
+			"default",
+			_http.Limits{},
+			nil,
+			`{"selector":"a","additionalBindings":[{"get":"/v1"},{"get":"/v2"}]}`,
+			http.StatusOK,
+			`{}`,
+		},
+		{
+			"body size",
+			_http.Limits{MaxBodySize: 16},
+			nil,
+			`{"selector":"example.Service.Method"}`,
+			http.StatusRequestEntityTooLarge,
+			`{"code":8,"message":"request body is larger than 16 bytes"}`,
+		},
+		{
+			"route body size",
+			_http.Limits{MaxBodySize: 16},
+			[]_http.RouteOption{_http.WithLimits(_http.Limits{MaxBodySize: 1024})},
+			`{"selector":"example.Service.Method"}`,
+			http.StatusOK,
+			`{}`,
+		},
+		{
+			"no body size limit",
+			_http.Limits{MaxBodySize: -1},
+			nil,
+			`{"selector":"` + strings.Repeat("a", 5<<20) + `"}`,
+			http.StatusOK,
+			`{}`,
+		},
+		{
+			"depth",
+			_http.Limits{MaxDepth: 2},
+			nil,
+			`{"additionalBindings":[{"get":"/v1"}]}`,
+			http.StatusBadRequest,
+			`{"code":3,"message":"JSON nesting depth is more than 2"}`,
+		},
+		{
+			"route depth",
+			_http.Limits{MaxDepth: 2},
+			[]_http.RouteOption{_http.WithLimits(_http.Limits{MaxDepth: 3})},
+			`{"additionalBindings":[{"get":"/v1"}]}`,
+			http.StatusOK,
+			`{}`,
+		},
+		{
+			"repeated",
+			_http.Limits{MaxRepeated: 1},
+			nil,
+			`{"additionalBindings":[{"get":"/v1"},{"get":"/v2"}]}`,
+			http.StatusBadRequest,
+			`{"code":3,"message":"JSON array has more than 1 elements"}`,
+		},
+		{
+			"repeated object fields are not elements",
+			_http.Limits{MaxRepeated: 1},
+			nil,
+			`{"selector":"a","body":"*","additionalBindings":[{"get":"/v1","body":"*"}]}`,
+			http.StatusOK,
+			`{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := _http.NewMap()
+			m.UseLimits(tt.limits)
+
+			if err := m.Add("POST", "/v1/rules", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				if err := _http.DecodeRequest(ctx, r, &annotations.HttpRule{}, "*"); err != nil {
+					_http.WriteError(ctx, w, r, err)
+
+					return
+				}
+
+				_, _ = w.Write([]byte(`{}`))
+			}, tt.route...); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/v1/rules", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+
+			m.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("ServeHTTP() code = %v, want %v", w.Code, tt.wantCode)
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ServeHTTP() = %s, want %s", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
@@ -2,58 +2,259 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/amsokol/protobuf-rest/runtime"
 )
 
 type Handler func(context.Context, http.ResponseWriter, *http.Request)
 
-type Paths map[runtime.Path]Handler
+// Middleware wraps Handler to add behaviour before and after it, e.g. logging or metrics.
+type Middleware func(Handler) Handler
 
-type Methods map[string]Paths
+type Methods map[string]Routes
 
 type Map struct {
-	Methods Methods // HTTP method -> path map
-}
+	Methods Methods // HTTP method -> routes sorted by precedence
 
-func (m *Map) Add(method string, template string, handler Handler) error {
-	pp, ok := m.Methods[method]
-	if !ok {
-		pp = make(Paths)
-		m.Methods[method] = pp
-	}
+	middlewares    []Middleware
+	interceptors   Interceptors            // interceptors of all routes
+	services       map[string]Interceptors // proto full service name -> service interceptors
+	marshalers     map[string]Marshaler    // MIME type -> marshaler
+	cors           *corsPolicy
+	accessLog      *AccessLog
+	panicHooks     []PanicHook
+	authenticators []Authenticator
+	rateLimiting   []RateLimiting
+	routeLimiting  []RateLimiting // limiting of routes with their own limit if rateLimiting is not set
+	headerMatcher  HeaderMatcher  // matcher of headers passed as incoming metadata, DefaultHeaderMatcher if nil
+	limits         Limits         // limits of request body decoding of all routes
+	timeout        time.Duration  // default timeout of all routes
+}
 
+func (m *Map) Add(method string, template string, handler Handler, opts ...RouteOption) error {
 	p, err := runtime.NewPath(template)
 	if err != nil {
 		return fmt.Errorf("add path template for '%s': %w", method, err)
 	}
 
-	pp[p] = handler
+	rr := m.Methods[method]
+
+	t := p.String()
+	for _, r := range rr {
+		if r.Path.String() == t {
+			return fmt.Errorf("add path template '%s' for '%s': %w", template, method, ErrDuplicateRoute)
+		}
+	}
+
+	r := &Route{
+		Method:  method,
+		Path:    p,
+		Handler: handler,
+		m:       m,
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	if r.rateLimit != nil && m.routeLimiting == nil {
+		m.routeLimiting = []RateLimiting{{Limiter: NewMemoryRateLimiter(), Key: KeyByRPC}}
+	}
+
+	rr = append(rr, r)
+
+	// keep routes sorted by precedence, routes with the same precedence
+	// are matched in order of registration
+	sort.SliceStable(rr, func(i, j int) bool {
+		return rr[i].Path.Precedes(rr[j].Path)
+	})
+
+	m.Methods[method] = rr
 
 	return nil
 }
 
 func (m *Map) Match(method string, urlPath string) (Handler, runtime.Values) {
-	pp, ok := m.Methods[method]
-	if !ok {
+	c := runtime.AcquireCaptures()
+	defer runtime.ReleaseCaptures(c)
+
+	r := m.Lookup(method, urlPath, c)
+	if r == nil {
 		return nil, nil
 	}
 
-	sp := strings.Split(strings.Trim(urlPath, "/"), "/")
+	return r.Handler, c.Values()
+}
+
+// Lookup returns route matched by HTTP method and url path, nil if there is no such route.
+// Path values are captured to c, use c.Values to convert them to map.
+// It does not allocate, c may be acquired with runtime.AcquireCaptures.
+func (m *Map) Lookup(method string, urlPath string, c *runtime.Captures) *Route {
+	p := strings.Trim(urlPath, "/")
 
-	for p, h := range pp {
-		v := make(runtime.Values)
-		if ok := p.Match(sp, v); ok {
-			return h, v
+	for _, r := range m.Methods[method] {
+		if r.Path.MatchPath(p, c) {
+			return r
 		}
 	}
 
-	return nil, nil
+	return nil
 }
 
-func NewMap() Map {
-	return Map{Methods: make(Methods)}
+// ServeHTTP dispatches the request to the handler of matched route through Map middlewares.
+// Matched route and path values are available from the handler context,
+// see RouteFromContext and ValuesFromContext. The context has deadline of request timeout
+// or default timeout of the route, see UseTimeout. Requests are authenticated and authorized
+// before the handler is called, see UseAuthenticator and WithPolicy, then rate limited, see UseRateLimiting.
+// Panics of the handler and middlewares are recovered with 500 Internal Server Error response, see UsePanicHook.
+func (m *Map) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.serveLogged(w, r, m.serve)
 }
+
+// serveLogged calls h with the request through the access log of the Map if any.
+func (m *Map) serveLogged(w http.ResponseWriter, r *http.Request, h Handler) {
+	if l := m.accessLog; l != nil {
+		l.handle(r.Context(), w, r, h)
+
+		return
+	}
+
+	h(r.Context(), w, r)
+}
+
+// serve dispatches the request to matched route, see ServeHTTP.
+func (m *Map) serve(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	if m.cors != nil && isPreflight(r) {
+		m.cors.preflight(w, r, m.pathMethods(r.URL.Path), false)
+
+		return
+	}
+
+	c := runtime.AcquireCaptures()
+	route := m.Lookup(r.Method, r.URL.Path, c)
+
+	var v runtime.Values
+	if route != nil && c.Len() > 0 {
+		v = c.Values()
+	}
+
+	runtime.ReleaseCaptures(c)
+
+	if route == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	if m.cors != nil {
+		m.cors.handle(w, r, false)
+	}
+
+	m.serveRoute(ctx, w, r, route, v)
+}
+
+// serveRoute calls handler of the route with deadline through authentication, rate limiting,
+// middlewares and panic recovery of the Map.
+func (m *Map) serveRoute(ctx context.Context, w http.ResponseWriter, r *http.Request, route *Route, v runtime.Values) {
+	// request context is canceled when client connection is closed
+	ctx, cancel, err := route.withDeadline(ctx, r)
+	defer cancel()
+
+	ctx = NewContext(ctx, route, v)
+	setRoute(ctx, route)
+
+	if err != nil {
+		WriteError(ctx, w, r, err)
+
+		return
+	}
+
+	h := m.authenticateHandler(route, m.rateLimitHandler(route, route.Handler))
+	for i := len(m.middlewares) - 1; i >= 0; i-- {
+		h = m.middlewares[i](h)
+	}
+
+	// panics of middlewares are recovered too, the access log records the recovered response
+	h = m.recoverHandler(h)
+
+	h(ctx, w, r.WithContext(ctx))
+}
+
+// Use adds middlewares to the Map. Middlewares are called in order they are added.
+func (m *Map) Use(mw ...Middleware) {
+	m.middlewares = append(m.middlewares, mw...)
+}
+
+// UseUnary adds unary interceptors that are called for all routes.
+func (m *Map) UseUnary(ii ...UnaryServerInterceptor) {
+	m.interceptors.Unary = append(m.interceptors.Unary, ii...)
+}
+
+// UseStream adds stream interceptors that are called for all routes.
+func (m *Map) UseStream(ii ...StreamServerInterceptor) {
+	m.interceptors.Stream = append(m.interceptors.Stream, ii...)
+}
+
+// UseServiceUnary adds unary interceptors that are called for routes of proto service,
+// service is proto full service name, e.g. "helloworld.Greeter".
+// Service interceptors are called after Map interceptors.
+func (m *Map) UseServiceUnary(service string, ii ...UnaryServerInterceptor) {
+	if m.services == nil {
+		m.services = make(map[string]Interceptors)
+	}
+
+	si := m.services[service]
+	si.Unary = append(si.Unary, ii...)
+	m.services[service] = si
+}
+
+// UseServiceStream adds stream interceptors that are called for routes of proto service,
+// service is proto full service name, e.g. "helloworld.Greeter".
+// Service interceptors are called after Map interceptors.
+func (m *Map) UseServiceStream(service string, ii ...StreamServerInterceptor) {
+	if m.services == nil {
+		m.services = make(map[string]Interceptors)
+	}
+
+	si := m.services[service]
+	si.Stream = append(si.Stream, ii...)
+	m.services[service] = si
+}
+
+// Routes returns registered routes sorted by HTTP method and precedence.
+func (m *Map) Routes() []RouteInfo {
+	var ri []RouteInfo
+
+	for _, method := range m.methods() {
+		for _, r := range m.Methods[method] {
+			ri = append(ri, r.Info())
+		}
+	}
+
+	return ri
+}
+
+// methods returns registered HTTP methods in sorted order.
+func (m *Map) methods() []string {
+	mm := make([]string, 0, len(m.Methods))
+	for method := range m.Methods {
+		mm = append(mm, method)
+	}
+
+	sort.Strings(mm)
+
+	return mm
+}
+
+// NewMap returns empty Map. Routes refer to the Map for its configuration, so the Map must not be copied.
+func NewMap() *Map {
+	return &Map{Methods: make(Methods)}
+}
+
+var ErrDuplicateRoute = errors.New("route is already registered")
@@ -2,8 +2,10 @@ package http_test
 
 import (
 	"context"
+	"errors"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 
@@ -41,6 +43,24 @@ func TestMap_Add(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"GET:/v1/{a}/{a}",
+			args{
+				"GET",
+				"/v1/{a}/{a}",
+				func(context.Context, http.ResponseWriter, *http.Request) {},
+			},
+			true,
+		},
+		{
+			"GET:/v1/{a=**}/{b}",
+			args{
+				"GET",
+				"/v1/{a=**}/{b}",
+				func(context.Context, http.ResponseWriter, *http.Request) {},
+			},
+			true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -65,6 +85,16 @@ func TestMap_Match(t *testing.T) {
 			"/v1/articles",
 			func(context.Context, http.ResponseWriter, *http.Request) {},
 		},
+		{
+			"GET",
+			"/v1/tables/{all=**}",
+			func(context.Context, http.ResponseWriter, *http.Request) {},
+		},
+		{
+			"GET",
+			"/v1/tables/{one}",
+			func(context.Context, http.ResponseWriter, *http.Request) {},
+		},
 	}
 
 	type args struct {
@@ -78,6 +108,28 @@ func TestMap_Match(t *testing.T) {
 		want  _http.Handler
 		want1 runtime.Values
 	}{
+		{
+			"GET:/v1/tables/data",
+			args{
+				"GET",
+				"/v1/tables/data",
+			},
+			func(context.Context, http.ResponseWriter, *http.Request) {},
+			runtime.Values{
+				"one": "data",
+			},
+		},
+		{
+			"GET:/v1/tables/data/items",
+			args{
+				"GET",
+				"/v1/tables/data/items",
+			},
+			func(context.Context, http.ResponseWriter, *http.Request) {},
+			runtime.Values{
+				"all": "data/items",
+			},
+		},
 		{
 			"GET:/v1/articles",
 			args{
@@ -129,6 +181,98 @@ func TestMap_Match(t *testing.T) {
 	}
 }
 
+func TestMap_Routes(t *testing.T) {
+	m := _http.NewMap()
+
+	h := func(context.Context, http.ResponseWriter, *http.Request) {}
+
+	for _, p := range []struct {
+		method   string
+		template string
+		opts     []_http.RouteOption
+	}{
+		{"POST", "/v1/articles", nil},
+		{"GET", "/v1/articles/{value=**}", nil},
+		{"GET", "/v1/articles/{value}", []_http.RouteOption{_http.WithRPC("example.Articles", "GetArticle")}},
+		{"GET", "/v1/articles/top", nil},
+	} {
+		if err := m.Add(p.method, p.template, h, p.opts...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := m.Add("GET", "/v1/articles/{value=*}", h); !errors.Is(err, _http.ErrDuplicateRoute) {
+		t.Errorf("Map.Add() error = %v, want %v", err, _http.ErrDuplicateRoute)
+	}
+
+	want := []_http.RouteInfo{
+		{Method: "GET", Template: "/v1/articles/top"},
+		{Method: "GET", Template: "/v1/articles/{value}", Service: "example.Articles", RPC: "GetArticle"},
+		{Method: "GET", Template: "/v1/articles/{value=**}"},
+		{Method: "POST", Template: "/v1/articles"},
+	}
+
+	got := m.Routes()
+	for i := range got {
+		if len(got[i].Handler) == 0 {
+			t.Errorf("Map.Routes() [%d] handler name is empty", i)
+		}
+
+		got[i].Handler = ""
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Map.Routes() = %v, want %v", got, want)
+	}
+}
+
+func TestMap_ServeHTTP(t *testing.T) {
+	var got []string
+
+	mw := func(name string) _http.Middleware {
+		return func(next _http.Handler) _http.Handler {
+			return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				got = append(got, name+":"+_http.RouteFromContext(ctx).Path.String())
+				next(ctx, w, r)
+			}
+		}
+	}
+
+	m := _http.NewMap()
+	m.Use(mw("first"), mw("second"))
+
+	if err := m.Add("GET", "/v1/articles/{value}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		got = append(got, "handler:"+_http.ValuesFromContext(r.Context())["value"])
+		w.WriteHeader(http.StatusNoContent)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/v1/articles/12345", nil))
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Map.ServeHTTP() code = %v, want %v", w.Code, http.StatusNoContent)
+	}
+
+	want := []string{
+		"first:/v1/articles/{value}",
+		"second:/v1/articles/{value}",
+		"handler:12345",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Map.ServeHTTP() calls = %v, want %v", got, want)
+	}
+
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/v1/books", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Map.ServeHTTP() code = %v, want %v", w.Code, http.StatusNotFound)
+	}
+}
+
 func BenchmarkPathMap_Match(b *testing.B) {
 	paths := []struct {
 		method   string
@@ -292,32 +436,119 @@ func BenchmarkPathMap_Match(b *testing.B) {
 
 	m := _http.NewMap()
 	for _, p := range paths {
-		if err := m.Add(p.method, p.template, p.handler); err != nil {
+		// "" and "/" are the same template
+		if err := m.Add(p.method, p.template, p.handler); err != nil && !errors.Is(err, _http.ErrDuplicateRoute) {
 			b.Fatal(err)
 		}
 	}
 
+	c := runtime.AcquireCaptures()
+
+	for _, tt := range tests {
+		got := m.Lookup(tt.args.method, tt.args.urlPath, c)
+
+		if (got == nil && tt.want != nil) || (got != nil && tt.want == nil) {
+			b.Fatalf("Map.Lookup('%s') got = %v, want %#v", tt.args.urlPath, got, tt.want)
+		}
+
+		var got1 runtime.Values
+		if got != nil {
+			got1 = c.Values()
+		}
+
+		if !reflect.DeepEqual(got1, tt.want1) {
+			b.Fatalf("Map.Lookup('%s') values = %v, want %v", tt.args.urlPath, got1, tt.want1)
+		}
+	}
+
+	runtime.ReleaseCaptures(c)
+
 	l := len(tests)
 
+	b.ReportAllocs()
 	b.ResetTimer()
 
 	b.RunParallel(func(pb *testing.PB) {
+		c := runtime.AcquireCaptures()
+		defer runtime.ReleaseCaptures(c)
+
 		for pb.Next() {
 			tt := tests[rand.Intn(l)]
 
-			// _, _ = m.Match(tt.args.method, tt.args.urlPath)
+			if got := m.Lookup(tt.args.method, tt.args.urlPath, c); (got == nil) != (tt.want == nil) {
+				b.Fatalf("Map.Lookup('%s') = %v", tt.args.urlPath, got)
+			}
+		}
+	})
+}
 
-			/* */
-			got, got1 := m.Match(tt.args.method, tt.args.urlPath)
+// literalPaths are templates of routes without path values.
+var literalPaths = []string{
+	"",
+	"/v1",
+	"/v1/articles",
+	"/v1/articles/top",
+	"/v1/books",
+	"/v1/books/articles",
+	"/v1/tables",
+	"/v1/jobs:cancel",
+}
 
-			if (got == nil && tt.want != nil) || (got != nil && tt.want == nil) {
-				b.Fatalf("Map.Match() got = %#v, want %#v", got, tt.want)
+func TestMap_Lookup(t *testing.T) {
+	m := _http.NewMap()
+	for _, p := range literalPaths {
+		if err := m.Add("GET", p, func(context.Context, http.ResponseWriter, *http.Request) {}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := m.Add("GET", "/v1/articles/{value}", func(context.Context, http.ResponseWriter, *http.Request) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := runtime.AcquireCaptures()
+	defer runtime.ReleaseCaptures(c)
+
+	for _, p := range append(literalPaths, "/v1/articles/12345") {
+		if allocs := testing.AllocsPerRun(100, func() {
+			if r := m.Lookup("GET", p, c); r == nil {
+				t.Fatalf("Map.Lookup('%s') = nil", p)
 			}
+		}); allocs != 0 {
+			t.Errorf("Map.Lookup('%s') allocs = %v, want 0", p, allocs)
+		}
+	}
 
-			if !reflect.DeepEqual(got1, tt.want1) {
-				b.Fatalf("Map.Match() got1 = %v, want %v", got1, tt.want1)
+	if r := m.Lookup("GET", "/v1/articles/12345/data", c); r != nil {
+		t.Errorf("Map.Lookup() = %v, want nil", r.Path)
+	}
+}
+
+func BenchmarkMap_Lookup(b *testing.B) {
+	m := _http.NewMap()
+	for _, p := range literalPaths {
+		if err := m.Add("GET", p, func(context.Context, http.ResponseWriter, *http.Request) {}); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	l := len(literalPaths)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		c := runtime.AcquireCaptures()
+		defer runtime.ReleaseCaptures(c)
+
+		i := rand.Intn(l)
+
+		for pb.Next() {
+			if r := m.Lookup("GET", literalPaths[i%l], c); r == nil {
+				b.Fatalf("Map.Lookup('%s') = nil", literalPaths[i%l])
 			}
-			/* */
+
+			i++
 		}
 	})
 }
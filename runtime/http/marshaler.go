@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"mime"
+	"net/http"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Marshaler encodes and decodes messages of HTTP content type.
+type Marshaler interface {
+	// ContentType returns value of Content-Type header of marshaled message.
+	ContentType() string
+	Marshal(m proto.Message) ([]byte, error)
+	Unmarshal(data []byte, m proto.Message) error
+}
+
+// JSONMarshaler is Marshaler of "application/json" content type.
+type JSONMarshaler struct {
+	MarshalOptions   protojson.MarshalOptions
+	UnmarshalOptions protojson.UnmarshalOptions
+}
+
+func (*JSONMarshaler) ContentType() string {
+	return "application/json"
+}
+
+func (j *JSONMarshaler) Marshal(m proto.Message) ([]byte, error) {
+	return j.MarshalOptions.Marshal(m)
+}
+
+func (j *JSONMarshaler) Unmarshal(data []byte, m proto.Message) error {
+	return j.UnmarshalOptions.Unmarshal(data, m)
+}
+
+// DefaultMarshaler is used if there is no marshaler registered for content type of request.
+// It unmarshals partial messages, DecodeRequest checks required fields
+// after path values and query parameters are set.
+var DefaultMarshaler Marshaler = &JSONMarshaler{
+	UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true, AllowPartial: true},
+}
+
+// ProtoNamesMarshaler is JSON Marshaler that uses proto field names instead of lowerCamelCase JSON names.
+var ProtoNamesMarshaler Marshaler = &JSONMarshaler{
+	MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true},
+	UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true, AllowPartial: true},
+}
+
+// WithMarshaler makes the route use mar instead of DefaultMarshaler
+// if there is no marshaler registered in the Map for content type of request.
+func WithMarshaler(mar Marshaler) RouteOption {
+	return func(r *Route) {
+		r.marshaler = mar
+	}
+}
+
+// RegisterMarshaler registers marshaler for MIME type, e.g. "application/json".
+func (m *Map) RegisterMarshaler(mimeType string, mar Marshaler) {
+	if m.marshalers == nil {
+		m.marshalers = make(map[string]Marshaler)
+	}
+
+	m.marshalers[mimeType] = mar
+}
+
+// MarshalerForRequest returns marshaler registered in the Map of the context
+// for Content-Type of the request. If there is no such marshaler it returns marshaler
+// of the route set with WithMarshaler or DefaultMarshaler.
+func MarshalerForRequest(ctx context.Context, r *http.Request) Marshaler {
+	route := RouteFromContext(ctx)
+	if route == nil {
+		return DefaultMarshaler
+	}
+
+	if route.m != nil && len(route.m.marshalers) > 0 {
+		if t, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil {
+			if mar, ok := route.m.marshalers[t]; ok {
+				return mar
+			}
+		}
+	}
+
+	if route.marshaler != nil {
+		return route.marshaler
+	}
+
+	return DefaultMarshaler
+}
+
+// RequestUnmarshaler is optionally implemented by Marshaler that decodes request body from HTTP request
+// instead of bytes, e.g. to read multipart body part by part. DecodeRequest uses it if the marshaler of request
+// implements it.
+type RequestUnmarshaler interface {
+	// UnmarshalRequest decodes body of request r to m. Size of the body must be limited by l.MaxBodySize.
+	UnmarshalRequest(r *http.Request, l Limits, m proto.Message) error
+}
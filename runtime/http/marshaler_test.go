@@ -0,0 +1,72 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+)
+
+func TestMarshalerForRequest(t *testing.T) {
+	registered := &_http.JSONMarshaler{}
+
+	tests := []struct {
+		name        string
+		contentType string
+		opts        []_http.RouteOption
+		want        _http.Marshaler
+	}{
+		{
+			"default",
+			"",
+			nil,
+			_http.DefaultMarshaler,
+		},
+		{
+			"registered",
+			"application/json; charset=utf-8",
+			[]_http.RouteOption{_http.WithMarshaler(_http.ProtoNamesMarshaler)},
+			registered,
+		},
+		{
+			"route",
+			"",
+			[]_http.RouteOption{_http.WithMarshaler(_http.ProtoNamesMarshaler)},
+			_http.ProtoNamesMarshaler,
+		},
+		{
+			"route unknown content type",
+			"text/plain",
+			[]_http.RouteOption{_http.WithMarshaler(_http.ProtoNamesMarshaler)},
+			_http.ProtoNamesMarshaler,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got _http.Marshaler
+
+			m := _http.NewMap()
+			m.RegisterMarshaler("application/json", registered)
+
+			if err := m.Add("POST", "/v1/items", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				got = _http.MarshalerForRequest(ctx, r)
+			}, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest("POST", "/v1/items", nil)
+			if len(tt.contentType) > 0 {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+
+			m.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Errorf("MarshalerForRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
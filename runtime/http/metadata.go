@@ -0,0 +1,139 @@
+package http
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// HTTPCodeMetadataKey is key of outgoing header metadata that sets HTTP status code
+	// of successful response, e.g. "201" or "302". Codes out of range 200-599 are ignored.
+	HTTPCodeMetadataKey = "x-http-code"
+
+	// HTTPHeaderMetadataPrefix is prefix of outgoing header metadata keys that set HTTP response headers
+	// with the prefix removed, e.g. "x-http-header-location" sets "Location".
+	HTTPHeaderMetadataPrefix = "x-http-header-"
+
+	// MetadataTrailerPrefix is prefix of HTTP headers that carry trailer metadata of the call.
+	MetadataTrailerPrefix = "Grpc-Trailer-"
+)
+
+// serverTransportStream captures header and trailer metadata set by service implementation
+// with grpc.SetHeader, grpc.SendHeader and grpc.SetTrailer during in-process call.
+type serverTransportStream struct {
+	method string
+
+	mu      sync.Mutex
+	header  metadata.MD
+	trailer metadata.MD
+}
+
+func (s *serverTransportStream) Method() string {
+	return s.method
+}
+
+func (s *serverTransportStream) SetHeader(md metadata.MD) error {
+	s.mu.Lock()
+	s.header = metadata.Join(s.header, md)
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *serverTransportStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *serverTransportStream) SetTrailer(md metadata.MD) error {
+	s.mu.Lock()
+	s.trailer = metadata.Join(s.trailer, md)
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *serverTransportStream) metadata() (metadata.MD, metadata.MD) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.header.Copy(), s.trailer.Copy()
+}
+
+// newServerTransportStreamContext returns context, service implementation can set
+// outgoing metadata to with grpc.SetHeader and grpc.SetTrailer.
+func newServerTransportStreamContext(ctx context.Context) context.Context {
+	return grpc.NewContextWithServerTransportStream(ctx, &serverTransportStream{
+		method: RouteFromContext(ctx).FullMethod(),
+	})
+}
+
+// writeMetadata sets HTTP response headers from outgoing metadata of the context.
+// First reserved keys are applied and stripped: HTTPHeaderMetadataPrefix keys set headers,
+// HTTPCodeMetadataKey sets returned HTTP status code. Then other header metadata is forwarded
+// with MetadataHeaderPrefix and trailer metadata with MetadataTrailerPrefix.
+// It returns 0 if HTTP status code is not set.
+func writeMetadata(ctx context.Context, w http.ResponseWriter) int {
+	s, ok := grpc.ServerTransportStreamFromContext(ctx).(*serverTransportStream)
+	if !ok {
+		return 0
+	}
+
+	header, trailer := s.metadata()
+	code := reservedMetadata(header, w.Header())
+
+	_ = reservedMetadata(trailer, w.Header())
+
+	forwardMetadata(header, MetadataHeaderPrefix, w.Header())
+	forwardMetadata(trailer, MetadataTrailerPrefix, w.Header())
+
+	return code
+}
+
+// reservedMetadata applies reserved keys of metadata to HTTP headers and removes them from metadata.
+func reservedMetadata(md metadata.MD, h http.Header) int {
+	var code int
+
+	for k, vv := range md {
+		switch {
+		case k == HTTPCodeMetadataKey:
+			if len(vv) > 0 {
+				if c, err := strconv.Atoi(vv[len(vv)-1]); err == nil && c >= http.StatusOK && c <= 599 {
+					code = c
+				}
+			}
+		case strings.HasPrefix(k, HTTPHeaderMetadataPrefix):
+			name := strings.TrimPrefix(k, HTTPHeaderMetadataPrefix)
+			h.Del(name)
+
+			for _, v := range vv {
+				h.Add(name, v)
+			}
+		default:
+			continue
+		}
+
+		delete(md, k)
+	}
+
+	return code
+}
+
+func forwardMetadata(md metadata.MD, prefix string, h http.Header) {
+	for k, vv := range md {
+		for _, v := range vv {
+			if strings.HasSuffix(k, "-bin") {
+				// binary metadata
+				v = base64.StdEncoding.EncodeToString([]byte(v))
+			}
+
+			h.Add(prefix+k, v)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestWriteResponse_metadata(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     metadata.MD
+		trailer    metadata.MD
+		err        error
+		wantCode   int
+		wantHeader http.Header
+	}{
+		{
+			"created",
+			metadata.Pairs(
+				"x-http-code", "201",
+				"x-http-header-location", "/v1/articles/12345",
+				"x-http-header-etag", `"abc"`,
+				"request-id", "42",
+			),
+			metadata.Pairs("checksum", "xyz"),
+			nil,
+			http.StatusCreated,
+			http.Header{
+				"Content-Type":             {"application/json"},
+				"Location":                 {"/v1/articles/12345"},
+				"Etag":                     {`"abc"`},
+				"Grpc-Metadata-Request-Id": {"42"},
+				"Grpc-Trailer-Checksum":    {"xyz"},
+			},
+		},
+		{
+			"redirect",
+			metadata.Pairs(
+				"x-http-code", "302",
+				"x-http-header-location", "/v2/articles/12345",
+				"x-http-header-cache-control", "no-cache",
+			),
+			nil,
+			nil,
+			http.StatusFound,
+			http.Header{
+				"Content-Type":  {"application/json"},
+				"Location":      {"/v2/articles/12345"},
+				"Cache-Control": {"no-cache"},
+			},
+		},
+		{
+			"invalid code",
+			metadata.Pairs("x-http-code", "created"),
+			nil,
+			nil,
+			http.StatusOK,
+			http.Header{
+				"Content-Type": {"application/json"},
+			},
+		},
+		{
+			"informational code",
+			metadata.Pairs("x-http-code", "101"),
+			nil,
+			nil,
+			http.StatusOK,
+			http.Header{
+				"Content-Type": {"application/json"},
+			},
+		},
+		{
+			"code out of range",
+			metadata.Pairs("x-http-code", "999"),
+			nil,
+			nil,
+			http.StatusOK,
+			http.Header{
+				"Content-Type": {"application/json"},
+			},
+		},
+		{
+			"error",
+			metadata.Pairs(
+				"x-http-code", "201",
+				"x-http-header-retry-after", "120",
+			),
+			nil,
+			status.Error(codes.Unavailable, "try later"),
+			http.StatusServiceUnavailable,
+			http.Header{
+				"Content-Type": {"application/json"},
+				"Retry-After":  {"120"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := _http.NewMap()
+			if err := m.Add("POST", "/v1/articles", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				ctx = _http.AnnotateContext(ctx, r)
+
+				if method, _ := grpc.Method(ctx); method != "/example.Articles/CreateArticle" {
+					t.Errorf("grpc.Method() = %v, want /example.Articles/CreateArticle", method)
+				}
+
+				if err := grpc.SetHeader(ctx, tt.header); err != nil {
+					t.Fatal(err)
+				}
+
+				if err := grpc.SetTrailer(ctx, tt.trailer); err != nil {
+					t.Fatal(err)
+				}
+
+				if tt.err != nil {
+					_http.WriteError(ctx, w, r, tt.err)
+				} else {
+					_http.WriteResponse(ctx, w, r, &annotations.HttpRule{}, "")
+				}
+			}, _http.WithRPC("example.Articles", "CreateArticle")); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, httptest.NewRequest("POST", "/v1/articles", nil))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("WriteResponse() code = %v, want %v", w.Code, tt.wantCode)
+			}
+
+			if !reflect.DeepEqual(w.Header(), tt.wantHeader) {
+				t.Errorf("WriteResponse() header = %v, want %v", w.Header(), tt.wantHeader)
+			}
+		})
+	}
+}
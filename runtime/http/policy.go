@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is domain of google.rpc.ErrorInfo details of errors of the runtime.
+var ErrorDomain = "protobuf-rest"
+
+// ReasonMissingScopes is reason of google.rpc.ErrorInfo of requests of principal that does not have
+// scopes required by Policy. Metadata of ErrorInfo has "method" and space-separated "missingScopes".
+const ReasonMissingScopes = "MISSING_SCOPES"
+
+// Policy is authorization policy of RPC method.
+type Policy struct {
+	Scopes []string // scopes the principal must have, empty if the method does not require authorization
+}
+
+// Policies are authorization policies by full RPC method, e.g. "/example.library.v1.LibraryService/GetBook".
+// Generated code declares Policies of services from (rest.auth) method options.
+type Policies map[string]Policy
+
+// WithPolicy sets authorization policy of the route. The Map checks it against principal of the request
+// after authentication, see UseAuthenticator.
+func WithPolicy(p Policy) RouteOption {
+	return func(r *Route) {
+		r.policy = p
+	}
+}
+
+// Policy returns authorization policy of the route.
+func (r *Route) Policy() Policy {
+	if r == nil {
+		return Policy{}
+	}
+
+	return r.policy
+}
+
+// Check returns nil if principal of the context satisfies the policy, UNAUTHENTICATED error
+// if the policy requires scopes and there is no principal, PERMISSION_DENIED error with google.rpc.ErrorInfo
+// details if the principal does not have some of the scopes.
+func (p Policy) Check(ctx context.Context, fullMethod string) error {
+	if len(p.Scopes) == 0 {
+		return nil
+	}
+
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	granted := make(map[string]struct{}, len(principal.Scopes))
+	for _, s := range principal.Scopes {
+		granted[s] = struct{}{}
+	}
+
+	var missing []string
+
+	for _, s := range p.Scopes {
+		if _, ok := granted[s]; !ok {
+			missing = append(missing, s)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	s, err := status.New(codes.PermissionDenied, "missing scopes: "+strings.Join(missing, ", ")).
+		WithDetails(&errdetails.ErrorInfo{
+			Reason:   ReasonMissingScopes,
+			Domain:   ErrorDomain,
+			Metadata: map[string]string{"method": fullMethod, "missingScopes": strings.Join(missing, " ")},
+		})
+	if err != nil {
+		return status.Errorf(codes.PermissionDenied, "missing scopes: %s", strings.Join(missing, ", "))
+	}
+
+	return s.Err()
+}
+
+// UnaryServerInterceptor returns gRPC interceptor that checks policies of native gRPC unary methods.
+// Principal must be set to the context by preceding authentication interceptor, see NewPrincipalContext.
+func (pp Policies) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := pp[info.FullMethod].Check(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns gRPC interceptor that checks policies of native gRPC streaming methods.
+// Principal must be set to the context by preceding authentication interceptor, see NewPrincipalContext.
+func (pp Policies) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := pp[info.FullMethod].Check(ss.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,130 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		scopes     string // scopes of principal, "-" if there is no principal
+		policy     _http.Policy
+		wantCode   int
+		wantInBody []string
+	}{
+		{"no policy", "-", _http.Policy{}, http.StatusOK, nil},
+		{"granted", "books.read books.write", _http.Policy{Scopes: []string{"books.read", "books.write"}}, http.StatusOK, nil},
+		{
+			"anonymous",
+			"-",
+			_http.Policy{Scopes: []string{"books.read"}},
+			http.StatusUnauthorized,
+			[]string{"authentication required"},
+		},
+		{
+			"missing scopes",
+			"books.read",
+			_http.Policy{Scopes: []string{"books.read", "books.write", "books.admin"}},
+			http.StatusForbidden,
+			[]string{
+				`"code":7`, "google.rpc.ErrorInfo", `"reason":"MISSING_SCOPES"`, `"domain":"protobuf-rest"`,
+				`"missingScopes":"books.write books.admin"`, `"method":"/example.library.v1.LibraryService/UpdateBook"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := _http.NewMap()
+			m.UseAuthenticator(_http.AuthenticatorFunc(func(ctx context.Context, r *http.Request) (*_http.Principal, error) {
+				if tt.scopes == "-" {
+					return nil, nil
+				}
+
+				return &_http.Principal{Subject: "alice", Scopes: strings.Fields(tt.scopes)}, nil
+			}))
+
+			var called bool
+
+			if err := m.Add("PUT", "/v1/books/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				called = true
+			}, _http.WithRPC("example.library.v1.LibraryService", "UpdateBook"), _http.WithPolicy(tt.policy)); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, httptest.NewRequest("PUT", "/v1/books/1", nil))
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("ServeHTTP() code = %v, want %v: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+
+			if called != (tt.wantCode == http.StatusOK) {
+				t.Errorf("ServeHTTP() handler called = %v", called)
+			}
+
+			// protojson output is not stable
+			body := strings.ReplaceAll(w.Body.String(), " ", "")
+
+			for _, s := range tt.wantInBody {
+				if !strings.Contains(body, strings.ReplaceAll(s, " ", "")) {
+					t.Errorf("ServeHTTP() = %s, want %s in body", w.Body.String(), s)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicies_UnaryServerInterceptor(t *testing.T) {
+	pp := _http.Policies{"/example.Service/Write": {Scopes: []string{"write"}}}
+	i := pp.UnaryServerInterceptor()
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		scopes   []string
+		wantCode codes.Code
+	}{
+		{"no policy", "/example.Service/Read", nil, codes.OK},
+		{"granted", "/example.Service/Write", []string{"write"}, codes.OK},
+		{"denied", "/example.Service/Write", []string{"read"}, codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := _http.NewPrincipalContext(context.Background(), &_http.Principal{Subject: "alice", Scopes: tt.scopes})
+
+			_, err := i(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("UnaryServerInterceptor() error = %v, want %v", err, tt.wantCode)
+			}
+
+			if err == nil {
+				return
+			}
+
+			details := status.Convert(err).Details()
+			if len(details) != 1 {
+				t.Fatalf("UnaryServerInterceptor() details = %v, want ErrorInfo", details)
+			}
+
+			if info, ok := details[0].(*errdetails.ErrorInfo); !ok || info.GetMetadata()["missingScopes"] != "write" {
+				t.Errorf("UnaryServerInterceptor() details = %v, want missing scope write", details[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package http
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RateLimit is rate of token bucket. Zero Rate means no limit.
+type RateLimit struct {
+	Rate  float64 // requests per second
+	Burst int     // maximum number of requests at once, Rate rounded up if zero
+}
+
+// burst returns size of token bucket.
+func (l RateLimit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+
+	return int(math.Max(1, math.Ceil(l.Rate)))
+}
+
+// RateLimiter is store of token buckets, e.g. in external storage shared by servers.
+type RateLimiter interface {
+	// Allow takes token from bucket of key with limit l. If there is no token it returns false
+	// and time after which the token is available.
+	Allow(ctx context.Context, key string, l RateLimit) (bool, time.Duration, error)
+}
+
+// RateLimitKey returns key of token bucket of the request. The context has matched route and principal.
+type RateLimitKey func(ctx context.Context, r *http.Request) string
+
+// KeyByRoute is RateLimitKey of HTTP method and path template of the route.
+func KeyByRoute(ctx context.Context, r *http.Request) string {
+	route := RouteFromContext(ctx)
+
+	return route.Method + " " + route.Path.String()
+}
+
+// KeyByRPC is RateLimitKey of full RPC method of the route, so all bindings of the method share the bucket.
+// Routes that do not serve RPC are keyed by route.
+func KeyByRPC(ctx context.Context, r *http.Request) string {
+	if fm := RouteFromContext(ctx).FullMethod(); len(fm) > 0 {
+		return fm
+	}
+
+	return KeyByRoute(ctx, r)
+}
+
+// KeyByClientIP is RateLimitKey of IP address of the client connection. Forwarding headers
+// are not trusted, servers behind proxy should set http.Request.RemoteAddr before the Map.
+func KeyByClientIP(ctx context.Context, r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// KeyByPrincipal is RateLimitKey of subject of authenticated principal,
+// requests without principal are keyed by client IP.
+func KeyByPrincipal(ctx context.Context, r *http.Request) string {
+	if p := PrincipalFromContext(ctx); p != nil {
+		return "principal:" + p.Subject
+	}
+
+	return "ip:" + KeyByClientIP(ctx, r)
+}
+
+// RateLimiting configures rate limiting of the Map.
+type RateLimiting struct {
+	Limiter RateLimiter  // store of token buckets, NewMemoryRateLimiter() if nil
+	Key     RateLimitKey // key of token bucket of the request, KeyByRoute if nil
+	Limit   RateLimit    // limit of routes without their own limit, see WithRateLimit
+}
+
+// UseRateLimiting adds rate limiting of requests of all routes. Requests are limited after authentication,
+// so buckets may be keyed by principal. Requests rejected by authentication or authorization are counted too,
+// without principal, so KeyByClientIP and KeyByPrincipal throttle credential guessing by client IP.
+// Requests over the limit get 429 Too Many Requests with Retry-After header and RESOURCE_EXHAUSTED status
+// with google.rpc.RetryInfo details. Each RateLimiting has its own buckets.
+func (m *Map) UseRateLimiting(rr ...RateLimiting) {
+	for _, rl := range rr {
+		if rl.Limiter == nil {
+			rl.Limiter = NewMemoryRateLimiter()
+		}
+
+		if rl.Key == nil {
+			rl.Key = KeyByRoute
+		}
+
+		m.rateLimiting = append(m.rateLimiting, rl)
+	}
+}
+
+// WithRateLimit sets rate limit of the route that overrides limit of rate limiting of the Map.
+// Buckets of the route are separate from buckets of other routes, e.g. of the same client IP,
+// but shared by routes of the same RPC. If the Map does not use rate limiting, the route is limited
+// with in-memory buckets keyed by RPC.
+func WithRateLimit(l RateLimit) RouteOption {
+	return func(r *Route) {
+		r.rateLimit = &l
+	}
+}
+
+// rateLimitOf returns rate limit of the route for rate limiting rl of the Map.
+func (r *Route) rateLimitOf(rl RateLimiting) RateLimit {
+	if r.rateLimit != nil {
+		return *r.rateLimit
+	}
+
+	return rl.Limit
+}
+
+// rateLimitingOf returns rate limiting of the route.
+func (m *Map) rateLimitingOf(route *Route) []RateLimiting {
+	if len(m.rateLimiting) == 0 && route.rateLimit != nil {
+		return m.routeLimiting
+	}
+
+	return m.rateLimiting
+}
+
+// rateLimitHandler returns handler that limits rate of requests of the route before h.
+func (m *Map) rateLimitHandler(route *Route, h Handler) Handler {
+	if len(m.rateLimitingOf(route)) == 0 {
+		return h
+	}
+
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		if m.allow(ctx, w, r, route) {
+			h(ctx, w, r)
+		}
+	}
+}
+
+// allow takes tokens of the request from buckets of rate limiting of the Map. If the request is over the limit
+// or the limiter fails it writes error response and returns false.
+func (m *Map) allow(ctx context.Context, w http.ResponseWriter, r *http.Request, route *Route) bool {
+	for i, rl := range m.rateLimitingOf(route) {
+		l := route.rateLimitOf(rl)
+		if l.Rate <= 0 {
+			continue
+		}
+
+		key := strconv.Itoa(i) + ":" + rl.Key(ctx, r)
+		if route.rateLimit != nil {
+			// routes with their own limit do not share buckets with other routes
+			key += ":" + KeyByRPC(ctx, r)
+		}
+
+		ok, retry, err := rl.Limiter.Allow(ctx, key, l)
+		if err != nil {
+			WriteError(ctx, w, r, status.Errorf(codes.Unavailable, "rate limiter: %v", err))
+
+			return false
+		}
+
+		if !ok {
+			WriteError(ctx, w, r, errRateLimited(w, retry))
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// errRateLimited sets Retry-After header and returns RESOURCE_EXHAUSTED error with google.rpc.RetryInfo.
+func errRateLimited(w http.ResponseWriter, retry time.Duration) error {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+
+	s, err := status.New(codes.ResourceExhausted, "rate limit exceeded").
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retry)})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	return s.Err()
+}
+
+// MemoryRateLimiter is RateLimiter that keeps token buckets in memory of the process.
+// Buckets that are full are removed periodically.
+type MemoryRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	swept   time.Time
+}
+
+type tokenBucket struct {
+	limit  RateLimit // limit of the last request
+	tokens float64
+	last   time.Time
+}
+
+// full reports whether bucket is refilled at time now.
+func (b *tokenBucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*b.limit.Rate >= float64(b.limit.burst())
+}
+
+// NewMemoryRateLimiter returns in-memory RateLimiter.
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{buckets: make(map[string]*tokenBucket), swept: time.Now()}
+}
+
+// memorySweepInterval is interval of removing full buckets of MemoryRateLimiter.
+const memorySweepInterval = time.Minute
+
+func (m *MemoryRateLimiter) Allow(ctx context.Context, key string, l RateLimit) (bool, time.Duration, error) {
+	now := time.Now()
+	burst := float64(l.burst())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.swept) > memorySweepInterval {
+		m.sweep(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		m.buckets[key] = b
+	}
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	b.last = now
+	b.limit = l
+
+	if b.tokens >= 1 {
+		b.tokens--
+
+		return true, 0, nil
+	}
+
+	return false, time.Duration((1 - b.tokens) / l.Rate * float64(time.Second)), nil
+}
+
+// sweep removes buckets that are not used for sweep interval and are full.
+func (m *MemoryRateLimiter) sweep(now time.Time) {
+	for k, b := range m.buckets {
+		if now.Sub(b.last) > memorySweepInterval && b.full(now) {
+			delete(m.buckets, k)
+		}
+	}
+
+	m.swept = now
+}
@@ -0,0 +1,394 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+)
+
+func TestUseRateLimiting(t *testing.T) {
+	type request struct {
+		path     string
+		addr     string // client IP
+		subject  string // principal, optional
+		wantCode int
+	}
+
+	// no refill during the test
+	limit := _http.RateLimit{Rate: 0.001, Burst: 2}
+
+	tests := []struct {
+		name     string
+		key      _http.RateLimitKey
+		routeOpt []_http.RouteOption // options of "/a"
+		requests []request
+	}{
+		{
+			"by route",
+			nil,
+			nil,
+			[]request{
+				{"/a", "10.0.0.1", "", http.StatusOK},
+				{"/a", "10.0.0.2", "", http.StatusOK},
+				{"/a", "10.0.0.1", "", http.StatusTooManyRequests},
+				{"/b", "10.0.0.1", "", http.StatusOK},
+			},
+		},
+		{
+			"by RPC",
+			_http.KeyByRPC,
+			nil,
+			[]request{
+				{"/a", "10.0.0.1", "", http.StatusOK},
+				{"/c", "10.0.0.1", "", http.StatusOK},
+				{"/a", "10.0.0.1", "", http.StatusTooManyRequests},
+				{"/b", "10.0.0.1", "", http.StatusOK},
+			},
+		},
+		{
+			"by client IP",
+			_http.KeyByClientIP,
+			nil,
+			[]request{
+				{"/a", "10.0.0.1", "", http.StatusOK},
+				{"/b", "10.0.0.1", "", http.StatusOK},
+				{"/a", "10.0.0.2", "", http.StatusOK},
+				{"/a", "10.0.0.1", "", http.StatusTooManyRequests},
+			},
+		},
+		{
+			"by principal",
+			_http.KeyByPrincipal,
+			nil,
+			[]request{
+				{"/a", "10.0.0.1", "alice", http.StatusOK},
+				{"/a", "10.0.0.2", "alice", http.StatusOK},
+				{"/a", "10.0.0.1", "bob", http.StatusOK},
+				{"/a", "10.0.0.1", "", http.StatusOK},
+				{"/a", "10.0.0.3", "alice", http.StatusTooManyRequests},
+			},
+		},
+		{
+			"route limit",
+			nil,
+			[]_http.RouteOption{_http.WithRateLimit(_http.RateLimit{Rate: 0.001, Burst: 1})},
+			[]request{
+				{"/a", "10.0.0.1", "", http.StatusOK},
+				{"/a", "10.0.0.1", "", http.StatusTooManyRequests},
+				{"/b", "10.0.0.1", "", http.StatusOK},
+			},
+		},
+		{
+			"route limit by client IP",
+			_http.KeyByClientIP,
+			[]_http.RouteOption{_http.WithRateLimit(_http.RateLimit{Rate: 0.001, Burst: 1})},
+			[]request{
+				{"/b", "10.0.0.1", "", http.StatusOK},
+				{"/a", "10.0.0.1", "", http.StatusOK},
+				{"/b", "10.0.0.1", "", http.StatusOK},
+				{"/a", "10.0.0.1", "", http.StatusTooManyRequests},
+				{"/b", "10.0.0.1", "", http.StatusTooManyRequests},
+				{"/a", "10.0.0.2", "", http.StatusOK},
+			},
+		},
+		{
+			"route without limit",
+			nil,
+			[]_http.RouteOption{_http.WithRateLimit(_http.RateLimit{})},
+			[]request{
+				{"/a", "10.0.0.1", "", http.StatusOK},
+				{"/a", "10.0.0.1", "", http.StatusOK},
+				{"/a", "10.0.0.1", "", http.StatusOK},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := _http.NewMap()
+			m.UseAuthenticator(_http.AuthenticatorFunc(func(ctx context.Context, r *http.Request) (*_http.Principal, error) {
+				if s := r.Header.Get("X-User"); len(s) > 0 {
+					return &_http.Principal{Subject: s}, nil
+				}
+
+				return nil, nil
+			}))
+			m.UseRateLimiting(_http.RateLimiting{Key: tt.key, Limit: limit})
+
+			h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) {}
+
+			for _, r := range []struct {
+				path string
+				opts []_http.RouteOption
+			}{
+				{"/a", append([]_http.RouteOption{_http.WithRPC("example.v1.Service", "A")}, tt.routeOpt...)},
+				{"/b", []_http.RouteOption{_http.WithRPC("example.v1.Service", "B")}},
+				{"/c", []_http.RouteOption{_http.WithRPC("example.v1.Service", "A")}},
+			} {
+				if err := m.Add("GET", r.path, h, r.opts...); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for i, req := range tt.requests {
+				r := httptest.NewRequest("GET", req.path, nil)
+				r.RemoteAddr = req.addr + ":1234"
+
+				if len(req.subject) > 0 {
+					r.Header.Set("X-User", req.subject)
+				}
+
+				w := httptest.NewRecorder()
+				m.ServeHTTP(w, r)
+
+				if w.Code != req.wantCode {
+					t.Fatalf("ServeHTTP() request %d code = %v, want %v: %s", i, w.Code, req.wantCode, w.Body.String())
+				}
+
+				if w.Code != http.StatusTooManyRequests {
+					continue
+				}
+
+				if got := w.Header().Get("Retry-After"); got != "1000" {
+					t.Errorf("ServeHTTP() Retry-After = %q, want %q", got, "1000")
+				}
+
+				// protojson output is not stable
+				body := strings.ReplaceAll(w.Body.String(), " ", "")
+
+				for _, s := range []string{`"code":8`, "google.rpc.RetryInfo", `"retryDelay":`} {
+					if !strings.Contains(body, s) {
+						t.Errorf("ServeHTTP() body = %s, want %s in it", w.Body.String(), s)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUseRateLimiting_rejected(t *testing.T) {
+	type request struct {
+		addr     string // client IP
+		apiKey   string // optional
+		wantCode int
+	}
+
+	tests := []struct {
+		name     string
+		key      _http.RateLimitKey
+		requests []request
+	}{
+		{
+			"by client IP",
+			_http.KeyByClientIP,
+			[]request{
+				{"10.0.0.1", "guess1", http.StatusUnauthorized},
+				{"10.0.0.1", "guess2", http.StatusUnauthorized},
+				{"10.0.0.1", "guess3", http.StatusTooManyRequests},
+				{"10.0.0.1", "valid", http.StatusTooManyRequests},
+				{"10.0.0.2", "valid", http.StatusOK},
+			},
+		},
+		{
+			"by principal",
+			_http.KeyByPrincipal,
+			[]request{
+				{"10.0.0.1", "guess1", http.StatusUnauthorized},
+				{"10.0.0.1", "", http.StatusUnauthorized},
+				{"10.0.0.1", "guess2", http.StatusTooManyRequests},
+				{"10.0.0.1", "valid", http.StatusOK},
+				{"10.0.0.1", "valid", http.StatusOK},
+				{"10.0.0.1", "valid", http.StatusTooManyRequests},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := _http.NewMap()
+			m.UseAuthenticator(&_http.APIKeyAuthenticator{
+				Header: "X-Api-Key",
+				Verify: func(ctx context.Context, key string) (*_http.Principal, error) {
+					if key != "valid" {
+						return nil, errors.New("invalid API key")
+					}
+
+					return &_http.Principal{Subject: "alice", Scopes: []string{"books.read"}}, nil
+				},
+			})
+			// no refill during the test
+			m.UseRateLimiting(_http.RateLimiting{Key: tt.key, Limit: _http.RateLimit{Rate: 0.001, Burst: 2}})
+
+			if err := m.Add("GET", "/v1/books", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {},
+				_http.WithPolicy(_http.Policy{Scopes: []string{"books.read"}})); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, req := range tt.requests {
+				r := httptest.NewRequest("GET", "/v1/books", nil)
+				r.RemoteAddr = req.addr + ":1234"
+
+				if len(req.apiKey) > 0 {
+					r.Header.Set("X-Api-Key", req.apiKey)
+				}
+
+				w := httptest.NewRecorder()
+				m.ServeHTTP(w, r)
+
+				if w.Code != req.wantCode {
+					t.Fatalf("ServeHTTP() request %d code = %v, want %v: %s", i, w.Code, req.wantCode, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	m := _http.NewMap()
+
+	h := func(ctx context.Context, w http.ResponseWriter, r *http.Request) {}
+
+	// no refill during the test, the Map does not use rate limiting
+	for _, r := range []struct {
+		path string
+		opts []_http.RouteOption
+	}{
+		{"/a", []_http.RouteOption{_http.WithRPC("example.v1.Service", "A"), _http.WithRateLimit(_http.RateLimit{Rate: 0.001, Burst: 1})}},
+		{"/b", []_http.RouteOption{_http.WithRPC("example.v1.Service", "B")}},
+	} {
+		if err := m.Add("GET", r.path, h, r.opts...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, req := range []struct {
+		path     string
+		wantCode int
+	}{
+		{"/a", http.StatusOK},
+		{"/b", http.StatusOK},
+		{"/a", http.StatusTooManyRequests},
+		{"/b", http.StatusOK},
+	} {
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, httptest.NewRequest("GET", req.path, nil))
+
+		if w.Code != req.wantCode {
+			t.Fatalf("ServeHTTP() request %d code = %v, want %v: %s", i, w.Code, req.wantCode, w.Body.String())
+		}
+	}
+}
+
+type rateLimiterFunc func(ctx context.Context, key string, l _http.RateLimit) (bool, time.Duration, error)
+
+func (f rateLimiterFunc) Allow(ctx context.Context, key string, l _http.RateLimit) (bool, time.Duration, error) {
+	return f(ctx, key, l)
+}
+
+func TestUseRateLimiting_limiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		limiter  rateLimiterFunc
+		wantCode int
+	}{
+		{"allowed", func(context.Context, string, _http.RateLimit) (bool, time.Duration, error) { return true, 0, nil }, http.StatusOK},
+		{
+			"denied",
+			func(context.Context, string, _http.RateLimit) (bool, time.Duration, error) {
+				return false, time.Second, nil
+			},
+			http.StatusTooManyRequests,
+		},
+		{
+			"store error",
+			func(context.Context, string, _http.RateLimit) (bool, time.Duration, error) {
+				return false, 0, errors.New("connection refused")
+			},
+			http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotKey string
+
+			m := _http.NewMap()
+			m.UseRateLimiting(_http.RateLimiting{
+				Limiter: rateLimiterFunc(func(ctx context.Context, key string, l _http.RateLimit) (bool, time.Duration, error) {
+					gotKey = key
+
+					return tt.limiter(ctx, key, l)
+				}),
+				Limit: _http.RateLimit{Rate: 1},
+			})
+
+			if err := m.Add("GET", "/v1/books/{id}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {}); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, httptest.NewRequest("GET", "/v1/books/1", nil))
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("ServeHTTP() code = %v, want %v: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+
+			if !strings.HasSuffix(gotKey, "GET /v1/books/{id}") {
+				t.Errorf("Allow() key = %q, want route key", gotKey)
+			}
+		})
+	}
+}
+
+func TestMemoryRateLimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   _http.RateLimit
+		n       int // requests allowed at once
+		refill  time.Duration
+		allowed bool // the next request is allowed after refill
+	}{
+		{"burst", _http.RateLimit{Rate: 1, Burst: 3}, 3, 0, false},
+		{"default burst", _http.RateLimit{Rate: 2.5}, 3, 0, false},
+		{"fractional rate", _http.RateLimit{Rate: 0.2}, 1, 0, false},
+		{"refill", _http.RateLimit{Rate: 100, Burst: 1}, 1, 50 * time.Millisecond, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := _http.NewMemoryRateLimiter()
+			ctx := context.Background()
+
+			for i := 0; i < tt.n; i++ {
+				if ok, _, err := l.Allow(ctx, "k", tt.limit); !ok || err != nil {
+					t.Fatalf("Allow() request %d = %v, %v, want allowed", i, ok, err)
+				}
+			}
+
+			if ok, _, _ := l.Allow(ctx, "other", tt.limit); !ok {
+				t.Errorf("Allow() of other key is not allowed")
+			}
+
+			time.Sleep(tt.refill)
+
+			ok, retry, err := l.Allow(ctx, "k", tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ok != tt.allowed {
+				t.Fatalf("Allow() = %v, want %v", ok, tt.allowed)
+			}
+
+			if max := time.Duration(float64(time.Second) / tt.limit.Rate); !ok && (retry <= 0 || retry > max) {
+				t.Errorf("Allow() retry = %v, want in (0, %v]", retry, max)
+			}
+		})
+	}
+}
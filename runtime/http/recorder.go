@@ -0,0 +1,123 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+type requestStateKey struct{}
+
+// requestState collects data of the request that is known to handler only, for Telemetry and access log.
+type requestState struct {
+	route   *Route        // matched route, nil if there is no such route
+	code    codes.Code    // gRPC status code of the response
+	hasCode bool          // code is known
+	req     proto.Message // decoded request message
+}
+
+// withRequestState returns context with state of the request, existing state of the context is reused,
+// so all instrumentation of the request shares it.
+func withRequestState(ctx context.Context) (context.Context, *requestState) {
+	if rs, ok := ctx.Value(requestStateKey{}).(*requestState); ok {
+		return ctx, rs
+	}
+
+	rs := &requestState{}
+
+	return context.WithValue(ctx, requestStateKey{}, rs), rs
+}
+
+// setRoute records matched route of the request of the context.
+func setRoute(ctx context.Context, route *Route) {
+	if rs, ok := ctx.Value(requestStateKey{}).(*requestState); ok {
+		rs.route = route
+	}
+}
+
+// setResponseCode records gRPC status code of the response of the request of the context.
+func setResponseCode(ctx context.Context, code codes.Code) {
+	if rs, ok := ctx.Value(requestStateKey{}).(*requestState); ok {
+		rs.code, rs.hasCode = code, true
+	}
+}
+
+// setRequestMessage records decoded request message of the request of the context.
+func setRequestMessage(ctx context.Context, req proto.Message) {
+	if rs, ok := ctx.Value(requestStateKey{}).(*requestState); ok {
+		rs.req = req
+	}
+}
+
+// responseCode returns gRPC status code of the response with HTTP status code,
+// successful responses that are not written with WriteError have OK code.
+func (rs *requestState) responseCode(httpStatus int) (codes.Code, bool) {
+	if !rs.hasCode && httpStatus < http.StatusBadRequest {
+		return codes.OK, true
+	}
+
+	return rs.code, rs.hasCode
+}
+
+// countingBody counts bytes read from request body.
+type countingBody struct {
+	io.ReadCloser
+	read int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+
+	return n, err
+}
+
+// recordingResponseWriter records status code and size of response.
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	code    int
+	written int64
+}
+
+func (w *recordingResponseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *recordingResponseWriter) Write(p []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(p)
+	w.written += int64(n)
+
+	return n, err
+}
+
+// Flush flushes response, so streamed responses are not buffered.
+func (w *recordingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the original ResponseWriter for http.ResponseController.
+func (w *recordingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// status returns HTTP status code of the response, 200 if nothing is written.
+func (w *recordingResponseWriter) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+
+	return w.code
+}
@@ -0,0 +1,118 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PanicHook is called with value and stack of panic recovered from handler of the route of the context.
+type PanicHook func(ctx context.Context, r *http.Request, p interface{}, stack []byte)
+
+// UsePanicHook adds hook that is called when middleware, handler of a route or service implementation panics,
+// e.g. to report the panic to error tracker. Hooks are called in order they are added.
+func (m *Map) UsePanicHook(hooks ...PanicHook) {
+	m.panicHooks = append(m.panicHooks, hooks...)
+}
+
+// handlerPanic is panic recovered in goroutine of handler with stack of the goroutine, see Invoke.
+type handlerPanic struct {
+	value interface{}
+	stack []byte
+}
+
+// String returns panic value with its stack, so panics outside the Map are printed with the panic site.
+func (p *handlerPanic) String() string {
+	return fmt.Sprintf("%v\n\n%s", p.value, p.stack)
+}
+
+type panicRequestKey struct{}
+
+// recoverHandler returns handler that recovers panics of h. The panic is logged with slog.Default()
+// and reported to hooks of the Map, response is google.rpc.Status with INTERNAL code
+// if nothing is written yet, otherwise the response is aborted. http.ErrAbortHandler is not recovered,
+// so streaming handlers abort responses with it.
+func (m *Map) recoverHandler(h Handler) Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		rw := &recordingResponseWriter{ResponseWriter: w}
+
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+
+			var stack []byte
+
+			if hp, ok := p.(*handlerPanic); ok {
+				p, stack = hp.value, hp.stack
+			} else {
+				stack = debug.Stack()
+			}
+
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			m.reportPanic(ctx, r, p, stack)
+
+			if rw.code != 0 {
+				// response is partially sent
+				panic(http.ErrAbortHandler)
+			}
+
+			WriteError(ctx, rw, r, status.Error(codes.Internal, "internal error"))
+		}()
+
+		// handlers that outlive the request report their panics with the request, see Invoke
+		h(context.WithValue(ctx, panicRequestKey{}, r), rw, r)
+	}
+}
+
+// reportPanic logs panic p of the request with slog.Default() and calls panic hooks of the Map if m is not nil.
+func (m *Map) reportPanic(ctx context.Context, r *http.Request, p interface{}, stack []byte) {
+	var method, template string
+	if r != nil {
+		method = r.Method
+	}
+
+	if route := RouteFromContext(ctx); route != nil {
+		template = route.Path.String()
+	}
+
+	slog.Default().ErrorContext(ctx, "panic in handler",
+		slog.String("method", method),
+		slog.String("route", template),
+		slog.Any("panic", p),
+		slog.String("stack", string(stack)),
+	)
+
+	if m == nil {
+		return
+	}
+
+	for _, hook := range m.panicHooks {
+		hook(ctx, r, p, stack)
+	}
+}
+
+// reportLatePanic reports panic of handler that returns after the request is done.
+func reportLatePanic(ctx context.Context, p interface{}, stack []byte) {
+	if p == http.ErrAbortHandler {
+		return
+	}
+
+	var m *Map
+	if route := RouteFromContext(ctx); route != nil {
+		m = route.m
+	}
+
+	r, _ := ctx.Value(panicRequestKey{}).(*http.Request)
+
+	m.reportPanic(ctx, r, p, stack)
+}
@@ -0,0 +1,146 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+)
+
+func TestMap_UsePanicHook(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	tests := []struct {
+		name       string
+		handler    _http.Handler
+		middleware _http.Middleware // optional
+		wantCode   int
+		wantBody   string
+		wantHook   interface{}
+		wantPanic  interface{}
+	}{
+		{
+			"panic",
+			func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				panic("boom")
+			},
+			nil,
+			http.StatusInternalServerError,
+			`{"code":13,"message":"internal error"}`,
+			"boom",
+			nil,
+		},
+		{
+			"panic after write",
+			func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("partial"))
+				panic("boom")
+			},
+			nil,
+			http.StatusOK,
+			"partial",
+			"boom",
+			http.ErrAbortHandler,
+		},
+		{
+			"abort",
+			func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				panic(http.ErrAbortHandler)
+			},
+			nil,
+			http.StatusOK,
+			"",
+			nil,
+			http.ErrAbortHandler,
+		},
+		{
+			"middleware panic",
+			func(ctx context.Context, w http.ResponseWriter, r *http.Request) {},
+			func(next _http.Handler) _http.Handler {
+				return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+					panic("boom")
+				}
+			},
+			http.StatusInternalServerError,
+			`{"code":13,"message":"internal error"}`,
+			"boom",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				log  bytes.Buffer
+				hook interface{}
+			)
+
+			m := _http.NewMap()
+			m.UseAccessLog(_http.AccessLog{Handler: slog.NewJSONHandler(&log, nil)})
+			m.UsePanicHook(func(ctx context.Context, r *http.Request, p interface{}, stack []byte) {
+				if _http.RouteFromContext(ctx) == nil || len(stack) == 0 {
+					t.Error("PanicHook() route or stack is not set")
+				}
+
+				hook = p
+			})
+
+			if tt.middleware != nil {
+				m.Use(tt.middleware)
+			}
+
+			if err := m.Add("GET", "/v1/panic", tt.handler); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+
+			func() {
+				defer func() {
+					if p := recover(); p != tt.wantPanic {
+						t.Errorf("ServeHTTP() panic = %v, want %v", p, tt.wantPanic)
+					}
+				}()
+
+				m.ServeHTTP(w, httptest.NewRequest("GET", "/v1/panic", nil))
+			}()
+
+			if w.Code != tt.wantCode {
+				t.Errorf("ServeHTTP() code = %v, want %v", w.Code, tt.wantCode)
+			}
+
+			if got := w.Body.String(); got != tt.wantBody && !equalJSON(t, got, tt.wantBody) {
+				t.Errorf("ServeHTTP() = %s, want %s", got, tt.wantBody)
+			}
+
+			if hook != tt.wantHook {
+				t.Errorf("PanicHook() panic = %v, want %v", hook, tt.wantHook)
+			}
+
+			if tt.wantCode == http.StatusInternalServerError && !strings.Contains(log.String(), `"code":"Internal"`) {
+				t.Errorf("UseAccessLog() = %s, want Internal code", log.String())
+			}
+		})
+	}
+}
+
+// equalJSON reports whether a and b are equal JSON values.
+func equalJSON(t *testing.T, a, b string) bool {
+	t.Helper()
+
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(va, vb)
+}
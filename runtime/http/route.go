@@ -0,0 +1,115 @@
+package http
+
+import (
+	"reflect"
+	goruntime "runtime"
+	"time"
+
+	"github.com/amsokol/protobuf-rest/runtime"
+)
+
+// Route is HTTP route registered in the Map.
+type Route struct {
+	Method  string       // HTTP method
+	Path    runtime.Path // path template
+	Service string       // proto full service name, e.g. "helloworld.Greeter", optional
+	RPC     string       // proto method name, e.g. "SayHello", optional
+	Handler Handler
+
+	m            *Map          // map the route is registered in
+	interceptors Interceptors  // route interceptors
+	marshaler    Marshaler     // route default marshaler
+	limits       Limits        // route limits of request body decoding
+	timeout      time.Duration // route default timeout
+	policy       Policy        // route authorization policy
+	rateLimit    *RateLimit    // route rate limit, nil if the route uses limits of the Map
+}
+
+// Routes is list of routes sorted by precedence.
+type Routes []*Route
+
+// RouteOption configures route added to the Map.
+type RouteOption func(*Route)
+
+// WithRPC sets proto service and method served by the route.
+func WithRPC(service string, method string) RouteOption {
+	return func(r *Route) {
+		r.Service = service
+		r.RPC = method
+	}
+}
+
+// FullMethod returns full RPC method string, i.e., /package.service/method.
+// It returns empty string if route does not serve RPC.
+func (r *Route) FullMethod() string {
+	if r == nil || len(r.Service) == 0 || len(r.RPC) == 0 {
+		return ""
+	}
+
+	return "/" + r.Service + "/" + r.RPC
+}
+
+// unaryInterceptors returns Map, service and route unary interceptors in order of calling.
+func (r *Route) unaryInterceptors() []UnaryServerInterceptor {
+	if r == nil {
+		return nil
+	}
+
+	var ii []UnaryServerInterceptor
+
+	if r.m != nil {
+		ii = append(ii, r.m.interceptors.Unary...)
+
+		if si, ok := r.m.services[r.Service]; ok {
+			ii = append(ii, si.Unary...)
+		}
+	}
+
+	return append(ii, r.interceptors.Unary...)
+}
+
+// streamInterceptors returns Map, service and route stream interceptors in order of calling.
+func (r *Route) streamInterceptors() []StreamServerInterceptor {
+	if r == nil {
+		return nil
+	}
+
+	var ii []StreamServerInterceptor
+
+	if r.m != nil {
+		ii = append(ii, r.m.interceptors.Stream...)
+
+		if si, ok := r.m.services[r.Service]; ok {
+			ii = append(ii, si.Stream...)
+		}
+	}
+
+	return append(ii, r.interceptors.Stream...)
+}
+
+// RouteInfo describes registered route.
+type RouteInfo struct {
+	Method   string `json:"method"`
+	Template string `json:"template"`
+	Service  string `json:"service,omitempty"`
+	RPC      string `json:"rpc,omitempty"`
+	Handler  string `json:"handler"` // handler function name
+}
+
+// Info returns route description.
+func (r *Route) Info() RouteInfo {
+	ri := RouteInfo{
+		Method:   r.Method,
+		Template: r.Path.String(),
+		Service:  r.Service,
+		RPC:      r.RPC,
+	}
+
+	if r.Handler != nil {
+		if f := goruntime.FuncForPC(reflect.ValueOf(r.Handler).Pointer()); f != nil {
+			ri.Handler = f.Name()
+		}
+	}
+
+	return ri
+}
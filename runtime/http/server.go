@@ -0,0 +1,190 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/amsokol/protobuf-rest/runtime"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCContentType is content type of native gRPC requests.
+const GRPCContentType = "application/grpc"
+
+// GRPCHandler returns handler that serves native gRPC requests with gs and other requests with h, e.g. Map,
+// so gRPC and REST are served on the same port. The handler accepts HTTP/2 cleartext (h2c) connections
+// with prior knowledge and HTTP/1.1 upgrade. HTTP/2 over TLS is negotiated by http.Server with ALPN.
+//
+// gRPC-Web requests and Connect unary requests of methods of gs on POST /<package>.<Service>/<Method>
+// are served with gs too, so the protocols share registered service implementations and gRPC interceptors.
+// If h is *Map, gRPC-Web and Connect requests are served as requests of a route of the method: its CORS policy,
+// including preflight requests, access log, authenticators, rate limiting, middlewares and panic recovery apply
+// to them, as well as policy, rate limit, timeout and limits of the first route of the method, see Map.ServeHTTP.
+// Errors of the Map are written in format of the protocol. Native gRPC requests are served by gs only,
+// use gRPC interceptors to authenticate them.
+func GRPCHandler(gs *grpc.Server, h http.Handler) http.Handler {
+	var (
+		once    sync.Once
+		methods map[string]bool // "/<package>.<Service>/<Method>" of gs
+	)
+
+	m, _ := h.(*Map)
+
+	return h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// services must be registered before the server is started
+		once.Do(func() {
+			methods = grpcMethods(gs)
+		})
+
+		switch {
+		case IsGRPCRequest(r):
+			gs.ServeHTTP(w, r)
+		case m != nil && m.cors != nil && isPreflight(r) && methods[r.URL.Path]:
+			m.serveLogged(w, r, func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				m.cors.preflight(w, r, []string{http.MethodPost}, true)
+			})
+		case IsGRPCWebRequest(r):
+			m.serveRPC(w, r, func(w http.ResponseWriter, r *http.Request, l Limits) {
+				serveGRPCWeb(gs, w, r, l)
+			}, writeGRPCWebError)
+		case isConnectRequest(methods, r):
+			m.serveRPC(w, r, func(w http.ResponseWriter, r *http.Request, l Limits) {
+				serveConnect(gs, w, r, l)
+			}, func(w http.ResponseWriter, r *http.Request, s *status.Status) {
+				writeConnectError(w, nil, s.Proto())
+			})
+		default:
+			h.ServeHTTP(w, r)
+		}
+	}), &http2.Server{})
+}
+
+// rpcErrorWriter writes error status s of gRPC-Web or Connect request r in format of the protocol.
+type rpcErrorWriter func(w http.ResponseWriter, r *http.Request, s *status.Status)
+
+type rpcErrorWriterKey struct{}
+
+// serveRPC serves gRPC-Web or Connect request with serve as request of a route of the method, see GRPCHandler.
+// Errors of the Map are written with writeError. Without Map the request is served with DefaultLimits.
+func (m *Map) serveRPC(w http.ResponseWriter, r *http.Request, serve func(w http.ResponseWriter, r *http.Request, l Limits),
+	writeError rpcErrorWriter,
+) {
+	if m == nil {
+		serve(w, r, DefaultLimits)
+
+		return
+	}
+
+	m.serveLogged(w, r, func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		if m.cors != nil {
+			m.cors.handle(w, r, true)
+		}
+
+		route := m.rpcRoute(r.URL.Path, func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+			serve(w, r, RouteFromContext(ctx).Limits())
+		})
+
+		m.serveRoute(context.WithValue(ctx, rpcErrorWriterKey{}, writeError), w, r, route, nil)
+	})
+}
+
+// rpcRoute returns route of POST request of gRPC method by full method name, i.e. "/<package>.<Service>/<Method>",
+// with handler h. The route has options of the first route of the method, if any.
+func (m *Map) rpcRoute(fullMethod string, h Handler) *Route {
+	route := &Route{m: m}
+
+	if r := m.methodRoute(fullMethod); r != nil {
+		*route = *r
+	} else if i := strings.LastIndexByte(fullMethod, '/'); i > 0 {
+		route.Service, route.RPC = fullMethod[1:i], fullMethod[i+1:]
+	}
+
+	route.Method = http.MethodPost
+	route.Handler = h
+
+	// full method is valid path template
+	route.Path, _ = runtime.NewPath(fullMethod)
+
+	return route
+}
+
+// methodRoute returns the first route of gRPC method by full method name, nil if the method has no routes.
+func (m *Map) methodRoute(fullMethod string) *Route {
+	for _, method := range m.methods() {
+		for _, r := range m.Methods[method] {
+			if r.FullMethod() == fullMethod {
+				return r
+			}
+		}
+	}
+
+	return nil
+}
+
+// grpcMethods returns paths of methods of gRPC server, i.e. "/<package>.<Service>/<Method>".
+func grpcMethods(gs *grpc.Server) map[string]bool {
+	methods := make(map[string]bool)
+
+	for name, si := range gs.GetServiceInfo() {
+		for _, mi := range si.Methods {
+			methods["/"+name+"/"+mi.Name] = true
+		}
+	}
+
+	return methods
+}
+
+// grpcRequest returns native gRPC request of content type ct with body, converted from request r
+// of other protocol, e.g. gRPC-Web.
+func grpcRequest(r *http.Request, ct string, body io.Reader) *http.Request {
+	req := r.Clone(r.Context())
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/2.0", 2, 0
+	req.Body = io.NopCloser(body)
+	req.ContentLength = -1
+
+	req.Header.Set("Content-Type", ct)
+	req.Header.Del("Content-Length")
+
+	return req
+}
+
+// mediaType returns media type of Content-Type of request, empty string if it is invalid.
+func mediaType(r *http.Request) string {
+	t, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return ""
+	}
+
+	return t
+}
+
+// IsGRPCRequest reports whether r is native gRPC request, i.e. HTTP/2 request of "application/grpc"
+// or "application/grpc+<codec>" content type.
+func IsGRPCRequest(r *http.Request) bool {
+	if r.ProtoMajor != 2 {
+		return false
+	}
+
+	t := mediaType(r)
+
+	return t == GRPCContentType || strings.HasPrefix(t, GRPCContentType+"+")
+}
+
+// NewServer returns HTTP server that serves gRPC requests with gs and other requests with h on addr,
+// see GRPCHandler. Use ListenAndServe for plaintext HTTP/1.1 and h2c, ListenAndServeTLS
+// for HTTPS with HTTP/2 negotiated with ALPN.
+func NewServer(addr string, gs *grpc.Server, h http.Handler) *http.Server {
+	return &http.Server{
+		Addr:      addr,
+		Handler:   GRPCHandler(gs, h),
+		TLSConfig: &tls.Config{NextProtos: []string{http2.NextProtoTLS, "http/1.1"}},
+	}
+}
@@ -0,0 +1,214 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestIsGRPCRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		protoMajor  int
+		contentType string
+		want        bool
+	}{
+		{"gRPC", 2, "application/grpc", true},
+		{"gRPC codec", 2, "application/grpc+proto", true},
+		{"HTTP/1.1", 1, "application/grpc", false},
+		{"JSON", 2, "application/json", false},
+		{"gRPC-Web", 2, "application/grpc-web", false},
+		{"no content type", 2, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/grpc.health.v1.Health/Check", nil)
+			r.ProtoMajor = tt.protoMajor
+			r.Header.Set("Content-Type", tt.contentType)
+
+			if got := _http.IsGRPCRequest(r); got != tt.want {
+				t.Errorf("IsGRPCRequest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGRPCHandler(t *testing.T) {
+	gs := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(gs, health.NewServer())
+
+	m := _http.NewMap()
+
+	if err := m.Add("GET", "/v1/shelves", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Proto)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		tls  bool
+	}{
+		{"h2c", false},
+		{"TLS", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := httptest.NewUnstartedServer(_http.GRPCHandler(gs, m))
+
+			var (
+				clients []*http.Client // HTTP/2 and HTTP/1.1 REST clients
+				creds   = grpc.WithInsecure()
+			)
+
+			if tt.tls {
+				s.EnableHTTP2 = true
+				s.StartTLS()
+
+				pool := x509.NewCertPool()
+				pool.AddCert(s.Certificate())
+
+				clients = []*http.Client{s.Client()}
+				creds = grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: pool}))
+			} else {
+				s.Start()
+
+				clients = []*http.Client{{Transport: &http2.Transport{
+					AllowHTTP: true,
+					DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+						return net.Dial(network, addr)
+					},
+				}}, http.DefaultClient}
+			}
+
+			defer s.Close()
+
+			conn, err := grpc.Dial(s.Listener.Addr().String(), creds)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer conn.Close()
+
+			resp, err := grpc_health_v1.NewHealthClient(conn).Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+			if err != nil {
+				t.Fatalf("gRPC Check() error = %v", err)
+			}
+
+			if resp.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+				t.Errorf("gRPC Check() = %v, want SERVING", resp.GetStatus())
+			}
+
+			for _, c := range clients {
+				r, err := c.Get(s.URL + "/v1/shelves")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				body, _ := io.ReadAll(r.Body)
+				r.Body.Close()
+
+				if r.StatusCode != http.StatusOK || string(body) != r.Proto {
+					t.Errorf("REST GET = %d %s, want 200 %s", r.StatusCode, body, r.Proto)
+				}
+			}
+		})
+	}
+}
+
+func TestGRPCHandler_mapPipeline(t *testing.T) {
+	gs := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(gs, health.NewServer())
+
+	data, err := proto.Marshal(&grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	grpcWeb := http.Header{"Content-Type": {"application/grpc-web+proto"}}
+	connect := http.Header{"Content-Type": {"application/proto"}}
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		body     []byte
+		apiKey   string // optional
+		wantCode int
+		want     string
+	}{
+		{"gRPC-Web without credentials", grpcWeb, frame(0, data), "", http.StatusOK, "grpc-status: 16\r\n"},
+		{"gRPC-Web without scope", grpcWeb, frame(0, data), "guest", http.StatusOK, "grpc-status: 7\r\n"},
+		{"gRPC-Web", grpcWeb, frame(0, data), "valid", http.StatusOK, "grpc-status: 0\r\n"},
+		{"Connect without credentials", connect, data, "", http.StatusUnauthorized, `"code":"unauthenticated"`},
+		{"Connect without scope", connect, data, "guest", http.StatusForbidden, `"code":"permission_denied"`},
+		{"Connect", connect, data, "valid", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log bytes.Buffer
+
+			m := _http.NewMap()
+			m.UseAccessLog(_http.AccessLog{Handler: slog.NewJSONHandler(&log, nil)})
+			m.UseAuthenticator(&_http.APIKeyAuthenticator{
+				Header: "X-Api-Key",
+				Verify: func(ctx context.Context, key string) (*_http.Principal, error) {
+					switch key {
+					case "valid":
+						return &_http.Principal{Subject: "alice", Scopes: []string{"health.read"}}, nil
+					case "guest":
+						return &_http.Principal{Subject: "guest"}, nil
+					default:
+						return nil, errors.New("invalid API key")
+					}
+				},
+			})
+
+			// policy of REST route of the method applies to gRPC-Web and Connect requests
+			if err := m.Add("GET", "/v1/health", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {},
+				_http.WithRPC("grpc.health.v1.Health", "Check"), _http.WithPolicy(_http.Policy{Scopes: []string{"health.read"}})); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/grpc.health.v1.Health/Check", bytes.NewReader(tt.body))
+
+			for k, vv := range tt.header {
+				r.Header[k] = vv
+			}
+
+			if len(tt.apiKey) > 0 {
+				r.Header.Set("X-Api-Key", tt.apiKey)
+			}
+
+			_http.GRPCHandler(gs, m).ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("ServeHTTP() = %v %q, want %v and %q in it", w.Code, w.Body.String(), tt.wantCode, tt.want)
+			}
+
+			if !strings.Contains(log.String(), `"rpc":"/grpc.health.v1.Health/Check"`) {
+				t.Errorf("UseAccessLog() = %s, want entry of the method", log.String())
+			}
+		})
+	}
+}
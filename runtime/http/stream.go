@@ -0,0 +1,170 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// StreamChunkSize is maximum size of data of google.api.HttpBody messages received from request body.
+const StreamChunkSize = 32 << 10
+
+// ServerStream is grpc.ServerStream of REST route of streaming method. Received messages are
+// google.api.HttpBody chunks of request body, sent google.api.HttpBody messages are written
+// to response body without buffering. Other sent message, e.g. response of client streaming
+// method, is written with WriteResponse.
+// It is used by generated code.
+type ServerStream struct {
+	ctx          context.Context
+	w            http.ResponseWriter
+	r            *http.Request
+	responseBody string
+
+	body        io.Reader // request body limited by route limits
+	read        int64     // size of received request body
+	wroteHeader bool
+}
+
+// NewServerStream returns stream of REST route of streaming method. The context must be
+// annotated with AnnotateContext. responseBody is field path of response message of client streaming method
+// that is written instead of the whole message.
+// It is used by generated code.
+func NewServerStream(ctx context.Context, w http.ResponseWriter, r *http.Request, responseBody string) *ServerStream {
+	return &ServerStream{
+		ctx:          ctx,
+		w:            w,
+		r:            r,
+		responseBody: responseBody,
+	}
+}
+
+func (s *ServerStream) SetHeader(md metadata.MD) error {
+	return grpc.SetHeader(s.ctx, md)
+}
+
+func (s *ServerStream) SendHeader(md metadata.MD) error {
+	return grpc.SendHeader(s.ctx, md)
+}
+
+func (s *ServerStream) SetTrailer(md metadata.MD) {
+	_ = grpc.SetTrailer(s.ctx, md)
+}
+
+func (s *ServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// SendMsg writes google.api.HttpBody message to response body and flushes it.
+// Content type of response is set from the first message. Other message is written as response of the method.
+func (s *ServerStream) SendMsg(m interface{}) error {
+	switch msg := m.(type) {
+	case *httpbody.HttpBody:
+		if !s.wroteHeader {
+			writeHTTPBodyHeader(s.ctx, s.w, msg)
+			s.wroteHeader = true
+		}
+
+		if _, err := s.w.Write(msg.GetData()); err != nil {
+			return status.Errorf(codes.Unavailable, "write response body: %v", err)
+		}
+
+		if f, ok := s.w.(http.Flusher); ok {
+			f.Flush()
+		}
+
+		if err := s.ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+
+		return nil
+	case proto.Message:
+		if s.wroteHeader {
+			return status.Error(codes.Internal, "response is already sent")
+		}
+
+		WriteResponse(s.ctx, s.w, s.r, msg, s.responseBody)
+		s.wroteHeader = true
+
+		return nil
+	default:
+		return status.Errorf(codes.Internal, "unsupported message type %T", m)
+	}
+}
+
+// RecvMsg reads next chunk of request body up to StreamChunkSize to google.api.HttpBody message
+// with content type of request. It returns io.EOF at the end of request body.
+// Total size of request body is limited by MaxBodySize of the route limits.
+func (s *ServerStream) RecvMsg(m interface{}) error {
+	hb, ok := m.(*httpbody.HttpBody)
+	if !ok {
+		return status.Errorf(codes.Internal, "unsupported message type %T", m)
+	}
+
+	l := RouteFromContext(s.ctx).Limits()
+
+	if s.body == nil {
+		s.body = s.r.Body
+		if l.MaxBodySize >= 0 {
+			if s.r.ContentLength > l.MaxBodySize {
+				return l.errBodySize()
+			}
+
+			s.body = io.LimitReader(s.r.Body, l.MaxBodySize+1)
+		}
+	}
+
+	data := make([]byte, StreamChunkSize)
+
+	n, err := io.ReadFull(s.body, data)
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return io.EOF
+	case err != nil && !errors.Is(err, io.ErrUnexpectedEOF):
+		return status.Errorf(codes.InvalidArgument, "read request body: %v", err)
+	}
+
+	s.read += int64(n)
+	if l.MaxBodySize >= 0 && s.read > l.MaxBodySize {
+		return l.errBodySize()
+	}
+
+	hb.ContentType = s.r.Header.Get("Content-Type")
+	hb.Data = data[:n]
+
+	return nil
+}
+
+// Finish completes response of streaming method that returned err. Error is written as response
+// if nothing is sent yet, otherwise the response is aborted, so client does not take it as complete.
+// It is used by generated code.
+func (s *ServerStream) Finish(err error) {
+	if err == nil {
+		if !s.wroteHeader {
+			code := writeMetadata(s.ctx, s.w)
+			if code == 0 {
+				code = http.StatusOK
+			}
+
+			s.w.WriteHeader(code)
+		}
+
+		return
+	}
+
+	if !s.wroteHeader {
+		WriteError(s.ctx, s.w, s.r, err)
+
+		return
+	}
+
+	panic(http.ErrAbortHandler)
+}
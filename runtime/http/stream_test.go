@@ -0,0 +1,112 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServerStream(t *testing.T) {
+	large := strings.Repeat("a", 2*_http.StreamChunkSize+1)
+
+	tests := []struct {
+		name       string
+		limits     _http.Limits
+		body       string
+		fail       bool // handler fails after echo
+		wantCode   int
+		wantChunks int
+		wantBody   string
+		wantPanic  bool
+	}{
+		{"echo", _http.Limits{}, "data", false, http.StatusOK, 1, "data", false},
+		{"chunks", _http.Limits{}, large, false, http.StatusOK, 3, large, false},
+		{"empty", _http.Limits{}, "", false, http.StatusOK, 0, "", false},
+		{"body size", _http.Limits{MaxBodySize: _http.StreamChunkSize}, large, false, http.StatusRequestEntityTooLarge, 0, "", false},
+		{"error before send", _http.Limits{}, "", true, http.StatusBadRequest, 0, "", false},
+		{"error after send", _http.Limits{}, "data", true, http.StatusOK, 1, "data", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var chunks int
+
+			m := _http.NewMap()
+			m.UseLimits(tt.limits)
+
+			if err := m.Add("POST", "/v1/echo", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				stream := _http.NewServerStream(ctx, w, r, "")
+
+				err := func() error {
+					for {
+						hb := &httpbody.HttpBody{}
+
+						err := stream.RecvMsg(hb)
+						if errors.Is(err, io.EOF) {
+							break
+						}
+
+						if err != nil {
+							return err
+						}
+
+						chunks++
+
+						if err := stream.SendMsg(&httpbody.HttpBody{ContentType: "text/plain", Data: hb.GetData()}); err != nil {
+							return err
+						}
+					}
+
+					if tt.fail {
+						return status.Error(codes.FailedPrecondition, "failed")
+					}
+
+					return nil
+				}()
+
+				stream.Finish(err)
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/v1/echo", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "text/plain")
+
+			func() {
+				defer func() {
+					if p := recover(); (p == http.ErrAbortHandler) != tt.wantPanic {
+						t.Errorf("ServeHTTP() panic = %v, want panic %v", p, tt.wantPanic)
+					}
+				}()
+
+				m.ServeHTTP(w, r)
+			}()
+
+			if w.Code != tt.wantCode {
+				t.Errorf("ServeHTTP() code = %v, want %v", w.Code, tt.wantCode)
+			}
+
+			if chunks != tt.wantChunks {
+				t.Errorf("RecvMsg() chunks = %v, want %v", chunks, tt.wantChunks)
+			}
+
+			if tt.wantCode == http.StatusOK && w.Body.String() != tt.wantBody {
+				t.Errorf("ServeHTTP() body size = %v, want %v", w.Body.Len(), len(tt.wantBody))
+			}
+
+			if tt.wantCode == http.StatusOK && !w.Flushed && tt.wantChunks > 0 {
+				t.Error("SendMsg() does not flush response")
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// ScopeName is instrumentation scope name of tracer and meter of Telemetry.
+const ScopeName = "github.com/amsokol/protobuf-rest/runtime/http"
+
+// TelemetryOption configures Telemetry.
+type TelemetryOption func(*telemetryOptions)
+
+type telemetryOptions struct {
+	tp          trace.TracerProvider
+	mp          metric.MeterProvider
+	propagators propagation.TextMapPropagator
+}
+
+// WithTracerProvider sets tracer provider of Telemetry, otel.GetTracerProvider() by default.
+func WithTracerProvider(tp trace.TracerProvider) TelemetryOption {
+	return func(o *telemetryOptions) {
+		o.tp = tp
+	}
+}
+
+// WithMeterProvider sets meter provider of Telemetry, otel.GetMeterProvider() by default.
+func WithMeterProvider(mp metric.MeterProvider) TelemetryOption {
+	return func(o *telemetryOptions) {
+		o.mp = mp
+	}
+}
+
+// WithPropagators sets propagators that extract trace context of the client from request headers,
+// otel.GetTextMapPropagator() by default.
+func WithPropagators(p propagation.TextMapPropagator) TelemetryOption {
+	return func(o *telemetryOptions) {
+		o.propagators = p
+	}
+}
+
+// Telemetry returns middleware that instruments routes of the Map with OpenTelemetry:
+// it starts server span named "<METHOD> <template>", e.g. "GET /v1/{name=shelves/*}", and records
+// request duration and sizes of request and response bodies to histograms
+// "http.server.request.duration", "http.server.request.body.size" and "http.server.response.body.size".
+// Spans and measurements have attributes of HTTP method, route template, HTTP status code
+// and gRPC status code of the response, spans of RPC routes also have proto service and method.
+// Instrument errors are reported to otel.Handle.
+//
+//	m.Use(http.Telemetry())
+func Telemetry(opts ...TelemetryOption) Middleware {
+	o := telemetryOptions{
+		tp:          otel.GetTracerProvider(),
+		mp:          otel.GetMeterProvider(),
+		propagators: otel.GetTextMapPropagator(),
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	t := &telemetry{
+		tracer:      o.tp.Tracer(ScopeName),
+		propagators: o.propagators,
+	}
+
+	meter := o.mp.Meter(ScopeName)
+
+	var err error
+
+	if t.duration, err = meter.Float64Histogram("http.server.request.duration",
+		metric.WithUnit("s"), metric.WithDescription("Duration of HTTP server requests.")); err != nil {
+		otel.Handle(err)
+	}
+
+	if t.requestSize, err = meter.Int64Histogram("http.server.request.body.size",
+		metric.WithUnit("By"), metric.WithDescription("Size of HTTP server request bodies.")); err != nil {
+		otel.Handle(err)
+	}
+
+	if t.responseSize, err = meter.Int64Histogram("http.server.response.body.size",
+		metric.WithUnit("By"), metric.WithDescription("Size of HTTP server response bodies.")); err != nil {
+		otel.Handle(err)
+	}
+
+	return t.middleware
+}
+
+type telemetry struct {
+	tracer       trace.Tracer
+	propagators  propagation.TextMapPropagator
+	duration     metric.Float64Histogram
+	requestSize  metric.Int64Histogram
+	responseSize metric.Int64Histogram
+}
+
+func (t *telemetry) middleware(next Handler) Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		route := RouteFromContext(ctx)
+
+		var template string
+		if route != nil {
+			template = route.Path.String()
+		}
+
+		attrs := []attribute.KeyValue{semconv.HTTPRequestMethodKey.String(r.Method), semconv.HTTPRoute(template)}
+
+		spanAttrs := append([]attribute.KeyValue{semconv.URLPath(r.URL.Path)}, attrs...)
+		if route.FullMethod() != "" {
+			spanAttrs = append(spanAttrs, semconv.RPCSystemGRPC, semconv.RPCService(route.Service), semconv.RPCMethod(route.RPC))
+		}
+
+		ctx = t.propagators.Extract(ctx, propagation.HeaderCarrier(r.Header))
+		ctx, span := t.tracer.Start(ctx, r.Method+" "+template,
+			trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(spanAttrs...))
+
+		ctx, rs := withRequestState(ctx)
+
+		body := &countingBody{ReadCloser: r.Body}
+		r = r.WithContext(ctx)
+		r.Body = body
+
+		rw := &recordingResponseWriter{ResponseWriter: w}
+		panicked := true
+
+		defer func() {
+			code := rw.status()
+			if panicked && rw.code == 0 {
+				// the Map recovers the panic with INTERNAL error
+				code = http.StatusInternalServerError
+				rs.code, rs.hasCode = codes.Internal, true
+			}
+
+			result := []attribute.KeyValue{semconv.HTTPResponseStatusCode(code)}
+
+			if c, ok := rs.responseCode(code); ok {
+				result = append(result, semconv.RPCGRPCStatusCodeKey.Int(int(c)))
+			}
+
+			attrs = append(attrs, result...)
+
+			span.SetAttributes(result...)
+			if code >= http.StatusInternalServerError {
+				span.SetStatus(otelcodes.Error, http.StatusText(code))
+			}
+
+			span.End()
+
+			// context of the request may be canceled
+			mctx := context.WithoutCancel(ctx)
+			set := metric.WithAttributes(attrs...)
+
+			t.duration.Record(mctx, time.Since(start).Seconds(), set)
+			t.requestSize.Record(mctx, body.read, set)
+			t.responseSize.Record(mctx, rw.written, set)
+		}()
+
+		next(ctx, rw, r)
+		panicked = false
+	}
+}
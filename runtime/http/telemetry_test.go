@@ -0,0 +1,212 @@
+package http_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTelemetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		body        string
+		traceparent string
+		wantSpan    string
+		wantAttrs   map[attribute.Key]attribute.Value
+		wantError   bool
+		wantReqSize int64
+		wantSize    int64
+	}{
+		{
+			"ok",
+			"POST",
+			"/v1/shelves/1/books",
+			`{"title":"Dune"}`,
+			"",
+			"POST /v1/{parent=shelves/*}/books",
+			map[attribute.Key]attribute.Value{
+				"http.request.method":       attribute.StringValue("POST"),
+				"http.route":                attribute.StringValue("/v1/{parent=shelves/*}/books"),
+				"http.response.status_code": attribute.IntValue(http.StatusOK),
+				"rpc.grpc.status_code":      attribute.IntValue(int(codes.OK)),
+				"rpc.service":               attribute.StringValue("example.library.v1.LibraryService"),
+				"rpc.method":                attribute.StringValue("CreateBook"),
+			},
+			false,
+			16,
+			2,
+		},
+		{
+			"error",
+			"GET",
+			"/v1/shelves/1",
+			"",
+			"",
+			"GET /v1/{name=shelves/*}",
+			map[attribute.Key]attribute.Value{
+				"http.route":                attribute.StringValue("/v1/{name=shelves/*}"),
+				"http.response.status_code": attribute.IntValue(http.StatusServiceUnavailable),
+				"rpc.grpc.status_code":      attribute.IntValue(int(codes.Unavailable)),
+			},
+			true,
+			0,
+			-1,
+		},
+		{
+			"panic",
+			"DELETE",
+			"/v1/shelves/1",
+			"",
+			"",
+			"DELETE /v1/{name=shelves/*}",
+			map[attribute.Key]attribute.Value{
+				"http.response.status_code": attribute.IntValue(http.StatusInternalServerError),
+				"rpc.grpc.status_code":      attribute.IntValue(int(codes.Internal)),
+			},
+			true,
+			0,
+			-1,
+		},
+		{
+			"remote parent",
+			"GET",
+			"/v1/shelves/1",
+			"",
+			"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+			"GET /v1/{name=shelves/*}",
+			nil,
+			true,
+			0,
+			-1,
+		},
+	}
+
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			reader := sdkmetric.NewManualReader()
+
+			m := _http.NewMap()
+			m.Use(_http.Telemetry(
+				_http.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))),
+				_http.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+				_http.WithPropagators(propagation.TraceContext{}),
+			))
+
+			if err := m.Add("POST", "/v1/{parent=shelves/*}/books", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				_, _ = r.Body.Read(make([]byte, 64))
+				_, _ = w.Write([]byte(`{}`))
+			}, _http.WithRPC("example.library.v1.LibraryService", "CreateBook")); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := m.Add("GET", "/v1/{name=shelves/*}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				_http.WriteError(ctx, w, r, status.Error(codes.Unavailable, "unavailable"))
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := m.Add("DELETE", "/v1/{name=shelves/*}", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				panic("boom")
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+
+			if len(tt.traceparent) > 0 {
+				r.Header.Set("Traceparent", tt.traceparent)
+			}
+
+			m.ServeHTTP(w, r)
+
+			spans := sr.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("Telemetry() spans = %v, want 1", len(spans))
+			}
+
+			span := spans[0]
+
+			if span.Name() != tt.wantSpan {
+				t.Errorf("Telemetry() span = %v, want %v", span.Name(), tt.wantSpan)
+			}
+
+			if span.SpanKind() != trace.SpanKindServer {
+				t.Errorf("Telemetry() span kind = %v, want server", span.SpanKind())
+			}
+
+			attrs := make(map[attribute.Key]attribute.Value)
+			for _, kv := range span.Attributes() {
+				attrs[kv.Key] = kv.Value
+			}
+
+			for k, v := range tt.wantAttrs {
+				if attrs[k] != v {
+					t.Errorf("Telemetry() span attribute %s = %v, want %v", k, attrs[k].Emit(), v.Emit())
+				}
+			}
+
+			if got := span.Status().Code == otelcodes.Error; got != tt.wantError {
+				t.Errorf("Telemetry() span error = %v, want %v", got, tt.wantError)
+			}
+
+			if len(tt.traceparent) > 0 && span.Parent().TraceID().String() != tt.traceparent[3:35] {
+				t.Errorf("Telemetry() span trace = %v, want %v", span.Parent().TraceID(), tt.traceparent[3:35])
+			}
+
+			var rm metricdata.ResourceMetrics
+			if err := reader.Collect(context.Background(), &rm); err != nil {
+				t.Fatal(err)
+			}
+
+			sums := make(map[string]float64)
+
+			for _, sm := range rm.ScopeMetrics {
+				for _, mm := range sm.Metrics {
+					switch data := mm.Data.(type) {
+					case metricdata.Histogram[float64]:
+						if len(data.DataPoints) != 1 || data.DataPoints[0].Count != 1 {
+							t.Errorf("Telemetry() %s = %v, want 1 point", mm.Name, data.DataPoints)
+						}
+					case metricdata.Histogram[int64]:
+						for _, dp := range data.DataPoints {
+							sums[mm.Name] += float64(dp.Sum)
+						}
+					}
+				}
+			}
+
+			if got := int64(sums["http.server.request.body.size"]); got != tt.wantReqSize {
+				t.Errorf("Telemetry() request size = %v, want %v", got, tt.wantReqSize)
+			}
+
+			if tt.wantSize >= 0 {
+				if got := int64(sums["http.server.response.body.size"]); got != tt.wantSize {
+					t.Errorf("Telemetry() response size = %v, want %v", got, tt.wantSize)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// GRPCTimeoutHeader is header of request timeout in gRPC format, e.g. "100m" is 100 milliseconds.
+	GRPCTimeoutHeader = "Grpc-Timeout"
+
+	// RequestTimeoutHeader is header of request timeout in seconds, e.g. "2.5", or Go duration, e.g. "2500ms".
+	RequestTimeoutHeader = "X-Request-Timeout"
+)
+
+// UseTimeout sets default timeout of all routes of the Map. Timeout of the route set with WithTimeout
+// takes precedence. Zero timeout means no default timeout. Clients may set shorter timeout, but not longer.
+func (m *Map) UseTimeout(d time.Duration) {
+	m.timeout = d
+}
+
+// WithTimeout sets default timeout of the route, e.g. for slow methods.
+func WithTimeout(d time.Duration) RouteOption {
+	return func(r *Route) {
+		r.timeout = d
+	}
+}
+
+// Timeout returns default timeout of the route, 0 if there is no default timeout.
+func (r *Route) Timeout() time.Duration {
+	if r == nil {
+		return 0
+	}
+
+	if r.timeout != 0 {
+		return r.timeout
+	}
+
+	if r.m != nil {
+		return r.m.timeout
+	}
+
+	return 0
+}
+
+// withDeadline returns context with deadline of request timeout set by client with GRPCTimeoutHeader
+// or RequestTimeoutHeader capped by default timeout of the route. Default timeout of the route is used
+// if client does not set timeout.
+func (r *Route) withDeadline(ctx context.Context, req *http.Request) (context.Context, context.CancelFunc, error) {
+	d, err := requestTimeout(req)
+	if err != nil {
+		return ctx, func() {}, status.Errorf(codes.InvalidArgument, "parse request timeout: %v", err)
+	}
+
+	if t := r.Timeout(); t > 0 && (d == 0 || t < d) {
+		d = t
+	}
+
+	if d == 0 {
+		return ctx, func() {}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d)
+
+	return ctx, cancel, nil
+}
+
+// requestTimeout returns request timeout set by client, 0 if it is not set.
+func requestTimeout(r *http.Request) (time.Duration, error) {
+	if s := r.Header.Get(GRPCTimeoutHeader); len(s) > 0 {
+		return parseGRPCTimeout(s)
+	}
+
+	if s := r.Header.Get(RequestTimeoutHeader); len(s) > 0 {
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			// Inf and NaN are parsed too, large values overflow time.Duration
+			if !(v > 0 && v*float64(time.Second) < math.MaxInt64) {
+				return 0, fmt.Errorf("invalid %s '%s': out of range", RequestTimeoutHeader, s)
+			}
+
+			return time.Duration(v * float64(time.Second)), nil
+		}
+
+		if d, err := time.ParseDuration(s); err == nil && d > 0 {
+			return d, nil
+		}
+
+		return 0, fmt.Errorf("invalid %s '%s'", RequestTimeoutHeader, s)
+	}
+
+	return 0, nil
+}
+
+// parseGRPCTimeout parses timeout of gRPC over HTTP2 protocol, i.e. up to 8 digits followed by unit.
+func parseGRPCTimeout(s string) (time.Duration, error) {
+	if len(s) < 2 || len(s) > 9 {
+		return 0, fmt.Errorf("invalid %s '%s'", GRPCTimeoutHeader, s)
+	}
+
+	unit, ok := _grpcTimeoutUnits[s[len(s)-1]]
+	if !ok {
+		return 0, fmt.Errorf("invalid %s '%s': unknown unit", GRPCTimeoutHeader, s)
+	}
+
+	v, err := strconv.ParseUint(s[:len(s)-1], 10, 64)
+	if err != nil || v == 0 {
+		return 0, fmt.Errorf("invalid %s '%s'", GRPCTimeoutHeader, s)
+	}
+
+	if v > uint64(math.MaxInt64/unit) {
+		// gRPC clamps timeouts that overflow time.Duration
+		return math.MaxInt64, nil
+	}
+
+	return time.Duration(v) * unit, nil
+}
+
+var _grpcTimeoutUnits = map[byte]time.Duration{
+	'H': time.Hour,
+	'M': time.Minute,
+	'S': time.Second,
+	'm': time.Millisecond,
+	'u': time.Microsecond,
+	'n': time.Nanosecond,
+}
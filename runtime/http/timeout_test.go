@@ -0,0 +1,228 @@
+package http_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	_http "github.com/amsokol/protobuf-rest/runtime/http"
+)
+
+func TestMap_UseTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		route    []_http.RouteOption
+		header   http.Header
+		want     time.Duration
+		wantCode int
+	}{
+		{"no timeout", 0, nil, nil, 0, http.StatusOK},
+		{"map", time.Minute, nil, nil, time.Minute, http.StatusOK},
+		{"route", time.Minute, []_http.RouteOption{_http.WithTimeout(time.Hour)}, nil, time.Hour, http.StatusOK},
+		{"grpc-timeout", 0, nil, http.Header{"Grpc-Timeout": {"2H"}}, 2 * time.Hour, http.StatusOK},
+		{"grpc-timeout shorter", time.Minute, nil, http.Header{"Grpc-Timeout": {"30S"}}, 30 * time.Second, http.StatusOK},
+		{"grpc-timeout capped", time.Minute, nil, http.Header{"Grpc-Timeout": {"2H"}}, time.Minute, http.StatusOK},
+		{
+			"grpc-timeout capped by route",
+			time.Minute,
+			[]_http.RouteOption{_http.WithTimeout(time.Hour)},
+			http.Header{"Grpc-Timeout": {"99999999H"}},
+			time.Hour,
+			http.StatusOK,
+		},
+		{"grpc-timeout milliseconds", 0, nil, http.Header{"Grpc-Timeout": {"1500m"}}, 1500 * time.Millisecond, http.StatusOK},
+		{"grpc-timeout precedes", 0, nil, http.Header{"Grpc-Timeout": {"3S"}, "X-Request-Timeout": {"10"}}, 3 * time.Second, http.StatusOK},
+		{"x-request-timeout seconds", time.Minute, nil, http.Header{"X-Request-Timeout": {"2.5"}}, 2500 * time.Millisecond, http.StatusOK},
+		{"x-request-timeout duration", 0, nil, http.Header{"X-Request-Timeout": {"1m30s"}}, 90 * time.Second, http.StatusOK},
+		{"x-request-timeout capped", time.Minute, nil, http.Header{"X-Request-Timeout": {"3600"}}, time.Minute, http.StatusOK},
+		{"invalid grpc-timeout unit", 0, nil, http.Header{"Grpc-Timeout": {"10x"}}, 0, http.StatusBadRequest},
+		{"invalid grpc-timeout value", 0, nil, http.Header{"Grpc-Timeout": {"123456789S"}}, 0, http.StatusBadRequest},
+		{"invalid x-request-timeout", 0, nil, http.Header{"X-Request-Timeout": {"-1"}}, 0, http.StatusBadRequest},
+		{"infinite x-request-timeout", 0, nil, http.Header{"X-Request-Timeout": {"Inf"}}, 0, http.StatusBadRequest},
+		{"nan x-request-timeout", 0, nil, http.Header{"X-Request-Timeout": {"NaN"}}, 0, http.StatusBadRequest},
+		{"overflowing x-request-timeout", 0, nil, http.Header{"X-Request-Timeout": {"1e300"}}, 0, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := _http.NewMap()
+			m.UseTimeout(tt.timeout)
+
+			var got time.Duration
+
+			if err := m.Add("GET", "/v1/shelves", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				if d, ok := ctx.Deadline(); ok {
+					got = time.Until(d)
+				}
+			}, tt.route...); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/v1/shelves", nil)
+
+			for k, vv := range tt.header {
+				r.Header[k] = vv
+			}
+
+			m.ServeHTTP(w, r)
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("ServeHTTP() code = %v, want %v", w.Code, tt.wantCode)
+			}
+
+			if got > tt.want || got < tt.want-time.Second {
+				t.Errorf("ServeHTTP() timeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvoke_deadline(t *testing.T) {
+	tests := []struct {
+		name     string
+		cancel   bool
+		wantCode int
+	}{
+		{"deadline exceeded", false, http.StatusGatewayTimeout},
+		{"client closed connection", true, 499},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := _http.NewMap()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			release := make(chan struct{})
+			defer close(release)
+
+			if err := m.Add("GET", "/v1/shelves", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+				_, err := _http.Invoke(ctx, nil, nil, func(_ context.Context, req interface{}) (interface{}, error) {
+					if tt.cancel {
+						cancel()
+					}
+
+					// the service ignores the context
+					<-release
+
+					return "response", nil
+				})
+				if err == nil {
+					t.Error("Invoke() error = nil")
+				}
+
+				_http.WriteError(ctx, w, r, err)
+			}, _http.WithTimeout(10*time.Millisecond)); err != nil {
+				t.Fatal(err)
+			}
+
+			w := httptest.NewRecorder()
+
+			m.ServeHTTP(w, httptest.NewRequest("GET", "/v1/shelves", nil).WithContext(ctx))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("ServeHTTP() code = %v, want %v", w.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+// panicking is service that panics, its frame is expected in the reported stack.
+func panicking(ctx context.Context, req interface{}) (interface{}, error) {
+	panic("boom")
+}
+
+func TestInvoke_panic(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	m := _http.NewMap()
+	m.UseTimeout(time.Minute)
+
+	var (
+		got   interface{}
+		stack []byte
+	)
+
+	m.UsePanicHook(func(ctx context.Context, r *http.Request, p interface{}, s []byte) {
+		got, stack = p, s
+	})
+
+	if err := m.Add("GET", "/v1/shelves", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		_, _ = _http.Invoke(ctx, nil, nil, panicking)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/v1/shelves", nil))
+
+	if w.Code != http.StatusInternalServerError || got != "boom" {
+		t.Errorf("ServeHTTP() code = %v, panic = %v, want 500 and boom", w.Code, got)
+	}
+
+	if !strings.Contains(string(stack), "http_test.panicking") {
+		t.Errorf("PanicHook() stack does not have panic site:\n%s", stack)
+	}
+}
+
+func TestInvoke_latePanic(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	m := _http.NewMap()
+
+	type report struct {
+		p     interface{}
+		r     *http.Request
+		stack []byte
+	}
+
+	reported := make(chan report, 1)
+
+	m.UsePanicHook(func(ctx context.Context, r *http.Request, p interface{}, stack []byte) {
+		reported <- report{p, r, stack}
+	})
+
+	release := make(chan struct{})
+
+	if err := m.Add("GET", "/v1/shelves", func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		_, err := _http.Invoke(ctx, nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+			<-release
+
+			return panicking(ctx, req)
+		})
+
+		_http.WriteError(ctx, w, r, err)
+	}, _http.WithTimeout(10*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/v1/shelves", nil))
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("ServeHTTP() code = %v, want %v", w.Code, http.StatusGatewayTimeout)
+	}
+
+	close(release)
+
+	select {
+	case got := <-reported:
+		if got.p != "boom" || got.r == nil || !strings.Contains(string(got.stack), "http_test.panicking") {
+			t.Errorf("PanicHook() panic = %v, request = %v, stack:\n%s", got.p, got.r, got.stack)
+		}
+	case <-time.After(time.Second):
+		t.Error("PanicHook() is not called for panic after deadline")
+	}
+}
@@ -21,13 +21,23 @@ The syntax `*` matches a single URL path segment.
 The syntax `**` matches zero or more URL path segments,
 which must be the last part of the URL path except the `Verb`.
 
+As an extension, `**` may be followed by LITERAL segments, e.g. "/v1/{name=**}/content".
+Only one `**` is allowed per template and every field may be bound only once.
+
 https://pkg.go.dev/google.golang.org/genproto/googleapis/api/annotations
 */
 func NewPath(template string) (Path, error) {
 	// normalize template
 	t := strings.TrimSpace(template)
 	t = strings.Trim(t, "/")
-	t = strings.ToLower(t) + "/"
+	t = strings.ToLower(t)
+
+	t, verb, err := splitVerb(t)
+	if err != nil {
+		return nil, fmt.Errorf("parse path template '%s': %w", template, err)
+	}
+
+	t += "/"
 
 	var (
 		b strings.Builder
@@ -74,5 +84,56 @@ func NewPath(template string) (Path, error) {
 		}
 	}
 
+	s.Verb = verb
+
+	if err := validatePath(p); err != nil {
+		return nil, fmt.Errorf("validate path template '%s': %w", template, err)
+	}
+
 	return p, nil
 }
+
+// splitVerb splits normalized template into segments and custom verb.
+func splitVerb(t string) (string, string, error) {
+	i := strings.LastIndexByte(t, ':')
+	if i < 0 || i < strings.LastIndexByte(t, '/') || i < strings.LastIndexByte(t, '}') {
+		// ':' is part of literal or variable
+		return t, "", nil
+	}
+
+	verb := t[i+1:]
+	if len(verb) == 0 || strings.ContainsAny(verb, "*{}") {
+		return "", "", fmt.Errorf("%w: '%s'", ErrInvalidVerbFormat, verb)
+	}
+
+	return t[:i], verb, nil
+}
+
+// validatePath checks "**" placement and field uniqueness.
+func validatePath(p Path) error {
+	var (
+		fields []string
+		ds     bool // "**" is found
+	)
+
+	for s := p; s != nil; s = s.Next {
+		if ds && (len(s.Field) > 0 || s.Value == "*" || s.Value == "**") {
+			return fmt.Errorf("%w: '%s'", ErrInvalidDoubleStarPlacement, s.Value)
+		}
+
+		if len(s.Field) > 0 && !s.IsVal {
+			// first segment of variable
+			for _, f := range fields {
+				if f == s.Field || strings.HasPrefix(s.Field, f+".") || strings.HasPrefix(f, s.Field+".") {
+					return fmt.Errorf("%w: '%s'", ErrDuplicateField, s.Field)
+				}
+			}
+
+			fields = append(fields, s.Field)
+		}
+
+		ds = ds || s.Value == "**"
+	}
+
+	return nil
+}
@@ -215,6 +215,64 @@ func TestNewPath(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"/v1/files/{path=**}/content",
+			args{
+				template: "/v1/files/{path=**}/content",
+			},
+			&runtime.Segment{
+				Value: "v1",
+				Next: &runtime.Segment{
+					Value: "files",
+					Next: &runtime.Segment{
+						Field: "path",
+						Value: "**",
+						Next: &runtime.Segment{
+							Value: "content",
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"/v1/jobs/{job}:cancel",
+			args{
+				template: "/v1/jobs/{job}:cancel",
+			},
+			&runtime.Segment{
+				Value: "v1",
+				Next: &runtime.Segment{
+					Value: "jobs",
+					Next: &runtime.Segment{
+						Field: "job",
+						Value: "*",
+						Verb:  "cancel",
+					},
+				},
+			},
+			false,
+		},
+		{
+			"/v1/{name=shelves/**}:undelete",
+			args{
+				template: "/v1/{name=shelves/**}:undelete",
+			},
+			&runtime.Segment{
+				Value: "v1",
+				Next: &runtime.Segment{
+					Field: "name",
+					Value: "shelves",
+					Next: &runtime.Segment{
+						Field: "name",
+						Value: "**",
+						IsVal: true,
+						Verb:  "undelete",
+					},
+				},
+			},
+			false,
+		},
 		{
 			"/ /",
 			args{
@@ -223,6 +281,62 @@ func TestNewPath(t *testing.T) {
 			nil,
 			true,
 		},
+		{
+			"/v1/jobs/{job}:",
+			args{
+				template: "/v1/jobs/{job}:",
+			},
+			nil,
+			true,
+		},
+		{
+			"/v1/{a}/{a}",
+			args{
+				template: "/v1/{a}/{a}",
+			},
+			nil,
+			true,
+		},
+		{
+			"/v1/{a}/{a.b}",
+			args{
+				template: "/v1/{a}/{a.b}",
+			},
+			nil,
+			true,
+		},
+		{
+			"/v1/{a=**}/{b}",
+			args{
+				template: "/v1/{a=**}/{b}",
+			},
+			nil,
+			true,
+		},
+		{
+			"/v1/{a=**/tail}",
+			args{
+				template: "/v1/{a=**/tail}",
+			},
+			nil,
+			true,
+		},
+		{
+			"/v1/**/*",
+			args{
+				template: "/v1/**/*",
+			},
+			nil,
+			true,
+		},
+		{
+			"/v1/**/**",
+			args{
+				template: "/v1/**/**",
+			},
+			nil,
+			true,
+		},
 		{
 			"/v1/articles/{value=/}",
 			args{
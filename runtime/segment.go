@@ -11,10 +11,22 @@ type Segment struct {
 	Value string   // url/value
 	Field string   // segment field name
 	IsVal bool     // indicates that segment is part of the "{field=value}" pattern value
+	Verb  string   // custom verb, set for the last segment of template only
 	Next  *Segment // next segment
 }
 
+// Match matches splitted url path against template started from the segment.
+//
+// Deprecated: use MatchPath, it does not allocate.
 func (s *Segment) Match(splittedPath []string, values Values) bool {
+	if len(s.Verb) > 0 {
+		// last segment in template - url must end with ":verb"
+		var ok bool
+		if splittedPath, ok = trimVerb(splittedPath, s.Verb); !ok {
+			return false
+		}
+	}
+
 	switch {
 	case strings.EqualFold(s.Value, "**"):
 		return s.doDoubleStar(splittedPath, values)
@@ -45,37 +57,58 @@ func (s *Segment) Match(splittedPath []string, values Values) bool {
 
 // Match: **.
 func (s *Segment) doDoubleStar(splittedPath []string, v Values) bool {
-	if len(s.Field) > 0 {
-		// this is field value
-		switch l := len(splittedPath); l {
-		case 0:
-			// empty value - nothing to do
-		case 1:
-			v.New(s.Field, splittedPath[0], s.IsVal)
-		default:
-			// compose field value
-			var (
-				val strings.Builder
-				i   int
-			)
-
-			// try to estimate buffer size to minimize allocations
-			val.Grow(len(splittedPath[0]) * l)
-
-			for i < l {
-				if i > 0 {
-					val.WriteRune('/')
-				}
+	if s.Next == nil {
+		// last segment in template - consume the rest of url
+		s.doubleStarValue(splittedPath, v)
 
-				val.WriteString(splittedPath[i])
-				i++
-			}
+		return true
+	}
+
+	// there are literal segments after "**" in template (see NewPath),
+	// backtrack from the longest possible match to find where they start
+	for i := len(splittedPath); i >= 0; i-- {
+		if s.Next.Match(splittedPath[i:], v) {
+			s.doubleStarValue(splittedPath[:i], v)
 
-			v.New(s.Field, val.String(), s.IsVal)
+			return true
 		}
 	}
 
-	return true
+	return false
+}
+
+// doubleStarValue saves url segments consumed by "**" as field value.
+func (s *Segment) doubleStarValue(splittedPath []string, v Values) {
+	if len(s.Field) == 0 {
+		return
+	}
+
+	switch l := len(splittedPath); l {
+	case 0:
+		// empty value - nothing to do
+	case 1:
+		v.New(s.Field, splittedPath[0], s.IsVal)
+	default:
+		// compose field value
+		var (
+			val strings.Builder
+			i   int
+		)
+
+		// try to estimate buffer size to minimize allocations
+		val.Grow(len(splittedPath[0]) * l)
+
+		for i < l {
+			if i > 0 {
+				val.WriteRune('/')
+			}
+
+			val.WriteString(splittedPath[i])
+			i++
+		}
+
+		v.New(s.Field, val.String(), s.IsVal)
+	}
 }
 
 // Match: *.
@@ -112,6 +145,341 @@ func (s *Segment) doStar(splittedPath []string, v Values) bool {
 	return s.Next.Match(splittedPath, v)
 }
 
+// MatchPath matches url path against template started from the segment, captured field values
+// are saved to c. Leading and trailing '/' of url path must be trimmed.
+// It does not allocate if c has enough capacity.
+func (s *Segment) MatchPath(urlPath string, c *Captures) bool {
+	c.Reset(urlPath)
+
+	return s.matchPath(urlPath, 0, c)
+}
+
+// matchPath matches url path started from offset i, i > len(urlPath) means there are no more url segments.
+func (s *Segment) matchPath(urlPath string, i int, c *Captures) bool {
+	if len(s.Verb) > 0 {
+		// last segment in template - url must end with ":verb"
+		if i > len(urlPath) {
+			return false
+		}
+
+		var ok bool
+		if urlPath, ok = trimPathVerb(urlPath, s.Verb); !ok || i > len(urlPath) {
+			return false
+		}
+	}
+
+	switch s.kind() {
+	case _kindDoubleStar:
+		return s.matchPathDoubleStar(urlPath, i, c)
+	case _kindStar:
+		return s.matchPathStar(urlPath, i, c)
+	}
+
+	if i > len(urlPath) {
+		// not matched
+		return false
+	}
+
+	end, next := nextSegment(urlPath, i)
+	if !strings.EqualFold(s.Value, urlPath[i:end]) {
+		// not matched
+		return false
+	}
+
+	// matched!
+
+	if len(s.Field) > 0 {
+		// this is field value
+		c.add(s.Field, i, end, s.IsVal)
+	}
+
+	if s.Next == nil {
+		// last segment in template
+		return next > len(urlPath)
+	}
+
+	// there are more segments in template
+	return s.Next.matchPath(urlPath, next, c)
+}
+
+// Match: **.
+func (s *Segment) matchPathDoubleStar(urlPath string, i int, c *Captures) bool {
+	if i > len(urlPath) {
+		// empty value - nothing to capture
+		return s.Next == nil || s.Next.matchPath(urlPath, i, c)
+	}
+
+	if s.Next == nil {
+		// last segment in template - consume the rest of url
+		if len(s.Field) > 0 {
+			c.add(s.Field, i, len(urlPath), s.IsVal)
+		}
+
+		return true
+	}
+
+	// there are literal segments after "**" in template (see NewPath),
+	// backtrack from the longest possible match to find where they start
+	n := len(c.cc)
+
+	for k := len(urlPath) + 1; ; {
+		if s.Next.matchPath(urlPath, k, c) {
+			if k > i && len(s.Field) > 0 {
+				c.add(s.Field, i, k-1, s.IsVal)
+			}
+
+			return true
+		}
+
+		c.cc = c.cc[:n]
+
+		if k <= i {
+			return false
+		}
+
+		// move to the start of previous url segment
+		if j := strings.LastIndexByte(urlPath[i:k-1], '/'); j < 0 {
+			k = i
+		} else {
+			k = i + j + 1
+		}
+	}
+}
+
+// Match: *.
+func (s *Segment) matchPathStar(urlPath string, i int, c *Captures) bool {
+	if i > len(urlPath) {
+		// empty value - nothing to capture
+		return s.Next == nil || s.Next.matchPath(urlPath, i, c)
+	}
+
+	end, next := nextSegment(urlPath, i)
+
+	if s.Next == nil && next <= len(urlPath) {
+		// last segment of template, url has more segments - not matched
+		return false
+	}
+
+	if len(s.Field) > 0 {
+		// this is field value
+		c.add(s.Field, i, end, s.IsVal)
+	}
+
+	if s.Next == nil {
+		return true
+	}
+
+	// move inside
+	return s.Next.matchPath(urlPath, next, c)
+}
+
+// nextSegment returns end of url segment started from offset i and offset of the next segment.
+func nextSegment(urlPath string, i int) (int, int) {
+	j := strings.IndexByte(urlPath[i:], '/')
+	if j < 0 {
+		return len(urlPath), len(urlPath) + 1
+	}
+
+	return i + j, i + j + 1
+}
+
+// trimPathVerb removes ":verb" suffix from url path.
+func trimPathVerb(urlPath string, verb string) (string, bool) {
+	i := len(urlPath) - len(verb) - 1
+	if i < 0 || urlPath[i] != ':' || !strings.EqualFold(urlPath[i+1:], verb) {
+		return "", false
+	}
+
+	return urlPath[:i], true
+}
+
+// Explain reports why url path does not match template started from the segment.
+// It returns nil if url path matches.
+func (s *Segment) Explain(splittedPath []string) error {
+	return s.explain(splittedPath, 1)
+}
+
+func (s *Segment) explain(splittedPath []string, pos int) error {
+	if len(s.Verb) > 0 {
+		var ok bool
+		if splittedPath, ok = trimVerb(splittedPath, s.Verb); !ok {
+			return fmt.Errorf("%w: url must end with ':%s'", ErrPathNotMatched, s.Verb)
+		}
+	}
+
+	switch {
+	case strings.EqualFold(s.Value, "**"):
+		if s.Next == nil {
+			return nil
+		}
+
+		urlPath := strings.Join(splittedPath, "/")
+
+		c := AcquireCaptures()
+		defer ReleaseCaptures(c)
+
+		// try suffixes from the shortest one, like matchPathDoubleStar does
+		for k := len(urlPath) + 1; ; {
+			c.Reset(urlPath)
+
+			if s.Next.matchPath(urlPath, k, c) {
+				return nil
+			}
+
+			if k == 0 {
+				break
+			}
+
+			// move to the start of previous url segment
+			k = strings.LastIndexByte(urlPath[:k-1], '/') + 1
+		}
+
+		return fmt.Errorf("%w: segment %d: suffix '%s' is not found", ErrPathNotMatched, pos, s.Next)
+	case strings.EqualFold(s.Value, "*"):
+		if s.Next == nil {
+			if len(splittedPath) > 1 {
+				return fmt.Errorf("%w: segment %d: unexpected segments '%s'",
+					ErrPathNotMatched, pos+1, strings.Join(splittedPath[1:], "/"))
+			}
+
+			return nil
+		}
+
+		if len(splittedPath) > 0 {
+			return s.Next.explain(splittedPath[1:], pos+1)
+		}
+
+		return s.Next.explain(splittedPath, pos)
+	}
+
+	if len(splittedPath) == 0 {
+		return fmt.Errorf("%w: segment %d: want '%s', url is too short", ErrPathNotMatched, pos, s.Value)
+	}
+
+	if !strings.EqualFold(s.Value, splittedPath[0]) {
+		return fmt.Errorf("%w: segment %d: want '%s', got '%s'", ErrPathNotMatched, pos, s.Value, splittedPath[0])
+	}
+
+	if s.Next == nil {
+		if len(splittedPath) > 1 {
+			return fmt.Errorf("%w: segment %d: unexpected segments '%s'",
+				ErrPathNotMatched, pos+1, strings.Join(splittedPath[1:], "/"))
+		}
+
+		return nil
+	}
+
+	return s.Next.explain(splittedPath[1:], pos+1)
+}
+
+// Precedes reports whether template started from the segment must be matched
+// before template started from segment o. Literal segments take precedence over "*"
+// and "*" takes precedence over "**". Template with verb takes precedence over
+// the same template without verb.
+func (s *Segment) Precedes(o *Segment) bool {
+	for s != nil && o != nil {
+		if ks, ko := s.kind(), o.kind(); ks != ko {
+			return ks < ko
+		}
+
+		if s.Next == nil && o.Next == nil {
+			return len(s.Verb) > 0 && len(o.Verb) == 0
+		}
+
+		s, o = s.Next, o.Next
+	}
+
+	// template that ends earlier is more specific than the one
+	// that continues with "*" or "**" but less specific than
+	// the one that continues with literal
+	switch {
+	case s == nil && o == nil:
+		return false
+	case s == nil:
+		return _kindEnd < o.kind()
+	default:
+		return s.kind() < _kindEnd
+	}
+}
+
+// segment kinds in order of precedence.
+const (
+	_kindLiteral = iota
+	_kindEnd
+	_kindStar
+	_kindDoubleStar
+)
+
+func (s *Segment) kind() int {
+	switch {
+	case strings.EqualFold(s.Value, "**"):
+		return _kindDoubleStar
+	case strings.EqualFold(s.Value, "*"):
+		return _kindStar
+	default:
+		return _kindLiteral
+	}
+}
+
+// String returns canonical template started from the segment.
+func (s *Segment) String() string {
+	var b strings.Builder
+
+	for c := s; c != nil; c = c.Next {
+		b.WriteRune('/')
+
+		if len(c.Field) == 0 {
+			b.WriteString(c.Value)
+		} else {
+			b.WriteRune('{')
+			b.WriteString(c.Field)
+
+			if c.Value != "*" || (c.Next != nil && c.Next.IsVal) {
+				// {field=value} pattern
+				b.WriteRune('=')
+				b.WriteString(c.Value)
+
+				for c.Next != nil && c.Next.IsVal {
+					c = c.Next
+					b.WriteRune('/')
+					b.WriteString(c.Value)
+				}
+			}
+
+			b.WriteRune('}')
+		}
+
+		if len(c.Verb) > 0 {
+			b.WriteRune(':')
+			b.WriteString(c.Verb)
+		}
+	}
+
+	return b.String()
+}
+
+// trimVerb removes ":verb" suffix from the last url segment.
+func trimVerb(splittedPath []string, verb string) ([]string, bool) {
+	l := len(splittedPath)
+	if l == 0 {
+		return nil, false
+	}
+
+	last := splittedPath[l-1]
+
+	i := len(last) - len(verb) - 1
+	if i < 0 || last[i] != ':' || !strings.EqualFold(last[i+1:], verb) {
+		return nil, false
+	}
+
+	// do not modify caller's slice
+	sp := make([]string, l)
+	copy(sp, splittedPath)
+	sp[l-1] = last[:i]
+
+	return sp, true
+}
+
 // 0 - matched string
 // 1 - {"field"=value}
 // 2 - {field="value"}
@@ -182,6 +550,10 @@ func newSegmentFieldValue(field string, value string) (*Segment, *Segment, error
 }
 
 var (
-	ErrInvalidSegmentFormat    = errors.New("invalid url segment format")
-	ErrInvalidFieldValueFormat = errors.New("invalid format of field value template")
+	ErrInvalidSegmentFormat       = errors.New("invalid url segment format")
+	ErrInvalidFieldValueFormat    = errors.New("invalid format of field value template")
+	ErrInvalidVerbFormat          = errors.New("invalid format of custom verb")
+	ErrInvalidDoubleStarPlacement = errors.New("'**' may be followed by literal segments and verb only")
+	ErrDuplicateField             = errors.New("field is bound more than once")
+	ErrPathNotMatched             = errors.New("url path does not match template")
 )
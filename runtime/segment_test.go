@@ -21,8 +21,11 @@ func TestSegment_Match(t *testing.T) {
 		"/v1/articles/{value=data2/symbol/**}", // 8
 		"/v1/books/articles/{value=data/items/*}",                 // 9
 		"/v1/books/articles/{value=data/items/*}/symbol/{number}", // 10
-		"/v1/tables/*",  // 11
-		"/v1/tables/**", // 12
+		"/v1/tables/*",                        // 11
+		"/v1/tables/**",                       // 12
+		"/v1/files/{path=**}/content",         // 13
+		"/v1/jobs/{job}:cancel",               // 14
+		"/v1/shelves/{name=books/**}:archive", // 15
 	}
 
 	pp := []runtime.Path{}
@@ -114,6 +117,60 @@ func TestSegment_Match(t *testing.T) {
 				"value": "data2/symbol/12345",
 			},
 		},
+		{
+			"/v1/files/dir/file.txt/content",
+			args{
+				"/v1/files/dir/file.txt/content",
+			},
+			13,
+			runtime.Values{
+				"path": "dir/file.txt",
+			},
+		},
+		{
+			"/v1/files/content",
+			args{
+				"/v1/files/content",
+			},
+			13,
+			runtime.Values{},
+		},
+		{
+			"/v1/files/dir/file.txt",
+			args{
+				"/v1/files/dir/file.txt",
+			},
+			-1,
+			runtime.Values{},
+		},
+		{
+			"/v1/jobs/42:cancel",
+			args{
+				"/v1/jobs/42:cancel",
+			},
+			14,
+			runtime.Values{
+				"job": "42",
+			},
+		},
+		{
+			"/v1/jobs/42",
+			args{
+				"/v1/jobs/42",
+			},
+			-1,
+			runtime.Values{},
+		},
+		{
+			"/v1/shelves/books/1/2:archive",
+			args{
+				"/v1/shelves/books/1/2:archive",
+			},
+			15,
+			runtime.Values{
+				"name": "books/1/2",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -141,3 +198,267 @@ func TestSegment_Match(t *testing.T) {
 		})
 	}
 }
+
+func TestSegment_String(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{
+			"",
+			"",
+			"/",
+		},
+		{
+			"/v1/articles/{value}/data",
+			"/v1/articles/{value}/data",
+			"/v1/articles/{value}/data",
+		},
+		{
+			"/v1/books/articles/{value=data/items/*}/symbol/{number}",
+			"/v1/books/articles/{value=data/items/*}/symbol/{number}",
+			"/v1/books/articles/{value=data/items/*}/symbol/{number}",
+		},
+		{
+			"/V1/Articles/{value=*}/",
+			"/V1/Articles/{value=*}/",
+			"/v1/articles/{value}",
+		},
+		{
+			"/v1/{name=shelves/**}:undelete",
+			"/v1/{name=shelves/**}:undelete",
+			"/v1/{name=shelves/**}:undelete",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := runtime.NewPath(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := p.String(); got != tt.want {
+				t.Errorf("Segment.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegment_Precedes(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want bool
+	}{
+		{
+			"literal before *",
+			"/v1/tables/data",
+			"/v1/tables/*",
+			true,
+		},
+		{
+			"* before literal",
+			"/v1/tables/*",
+			"/v1/tables/data",
+			false,
+		},
+		{
+			"* before **",
+			"/v1/tables/*",
+			"/v1/tables/**",
+			true,
+		},
+		{
+			"end before *",
+			"/v1/tables",
+			"/v1/tables/*",
+			true,
+		},
+		{
+			"literal suffix before end",
+			"/v1/{name=**}/content",
+			"/v1/{name=**}",
+			true,
+		},
+		{
+			"verb before no verb",
+			"/v1/jobs/{job}:cancel",
+			"/v1/jobs/{job}",
+			true,
+		},
+		{
+			"equal",
+			"/v1/jobs/{job}",
+			"/v1/jobs/{id}",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := runtime.NewPath(tt.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := runtime.NewPath(tt.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := a.Precedes(b); got != tt.want {
+				t.Errorf("Segment.Precedes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegment_Explain(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		path     string
+		want     string
+	}{
+		{
+			"matched",
+			"/v1/articles/{value}",
+			"/v1/articles/12345",
+			"",
+		},
+		{
+			"literal",
+			"/v1/articles/{value}",
+			"/v1/article/12345",
+			"url path does not match template: segment 2: want 'articles', got 'article'",
+		},
+		{
+			"too short",
+			"/v1/articles/{value}/data",
+			"/v1/articles/12345",
+			"url path does not match template: segment 4: want 'data', url is too short",
+		},
+		{
+			"too long",
+			"/v1/articles/{value}",
+			"/v1/articles/12345/data",
+			"url path does not match template: segment 4: unexpected segments 'data'",
+		},
+		{
+			"suffix",
+			"/v1/files/{path=**}/content",
+			"/v1/files/a/b",
+			"url path does not match template: segment 3: suffix '/content' is not found",
+		},
+		{
+			"verb",
+			"/v1/jobs/{job}:cancel",
+			"/v1/jobs/42",
+			"url path does not match template: url must end with ':cancel'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := runtime.NewPath(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got string
+			if err := p.Explain(strings.Split(strings.Trim(tt.path, "/"), "/")); err != nil {
+				got = err.Error()
+			}
+
+			if got != tt.want {
+				t.Errorf("Segment.Explain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegment_MatchPath(t *testing.T) {
+	tt := []string{
+		"",
+		"/v1",
+		"/v1/articles",
+		"/v1/articles/{value}",
+		"/v1/articles/{value}/data",
+		"/v1/articles/{value=data/*}",
+		"/v1/articles/{value=data1/*/*/*}",
+		"/v1/articles/{value=data2/symbol/**}",
+		"/v1/books/articles/{value=data/items/*}/symbol/{number}",
+		"/v1/tables/*",
+		"/v1/tables/**",
+		"/v1/tables/*/{id}",
+		"/v1/files/{path=**}/content",
+		"/v1/files/**/content/raw",
+		"/v1/jobs/{job}:cancel",
+		"/v1/shelves/{name=books/**}:archive",
+		"/v1/{name=**}:undelete",
+	}
+
+	pp := []string{
+		"",
+		"/",
+		"/v1",
+		"/v1/",
+		"/v1/articles",
+		"/v1/articles/12345",
+		"/v1/articles/12345/data",
+		"/v1/articles/data/12345",
+		"/v1/articles/data1/1/2/3",
+		"/v1/articles/data1/1/2",
+		"/v1/articles/data2/symbol",
+		"/v1/articles/data2/symbol/a/b/c",
+		"/v1/books/articles/data/items/1/symbol/2",
+		"/v1/tables",
+		"/v1/tables/a",
+		"/v1/tables/a/b",
+		"/v1/tables/a//b",
+		"/v1/files/content",
+		"/v1/files/a/content",
+		"/v1/files/a/b/content",
+		"/v1/files/content/content",
+		"/v1/files/a/content/raw",
+		"/v1/files/a/b",
+		"/v1/jobs/42:cancel",
+		"/v1/jobs/42",
+		"/v1/jobs/:cancel",
+		"/v1/jobs:cancel",
+		"/v1/shelves/books:archive",
+		"/v1/shelves/books/1/2:archive",
+		"/v1/a/b:undelete",
+		"/v1:undelete",
+	}
+
+	c := &runtime.Captures{}
+
+	for _, template := range tt {
+		p, err := runtime.NewPath(template)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, path := range pp {
+			urlPath := strings.Trim(path, "/")
+
+			want1 := make(runtime.Values)
+			want := p.Match(strings.Split(urlPath, "/"), want1)
+
+			got := p.MatchPath(urlPath, c)
+			if got != want {
+				t.Errorf("Segment.MatchPath('%s', '%s') = %v, want %v", template, path, got, want)
+
+				continue
+			}
+
+			if got1 := c.Values(); got && !reflect.DeepEqual(got1, want1) {
+				t.Errorf("Segment.MatchPath('%s', '%s') got1 = %v, want %v", template, path, got1, want1)
+			}
+		}
+	}
+}
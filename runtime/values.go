@@ -1,6 +1,10 @@
 package runtime
 
-import "strings"
+import (
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
 
 type Values map[string]string
 
@@ -13,3 +17,14 @@ func (vv Values) New(key string, value string, add bool) {
 
 	vv[key] = value
 }
+
+// Populate sets fields of message m from values, keys are field paths.
+func (vv Values) Populate(m proto.Message) error {
+	for k, v := range vv {
+		if err := PopulateField(m.ProtoReflect(), k, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/amsokol/protobuf-rest/runtime"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestValues_New(t *testing.T) {
@@ -71,3 +73,47 @@ func TestValues_New(t *testing.T) {
 		})
 	}
 }
+
+func TestValues_Populate(t *testing.T) {
+	tests := []struct {
+		name    string
+		vv      runtime.Values
+		want    proto.Message
+		wantErr bool
+	}{
+		{
+			"fields",
+			runtime.Values{
+				"selector":    "example.Service.Method",
+				"custom.path": "v1/items/12345",
+			},
+			&annotations.HttpRule{
+				Selector: "example.Service.Method",
+				Pattern:  &annotations.HttpRule_Custom{Custom: &annotations.CustomHttpPattern{Path: "v1/items/12345"}},
+			},
+			false,
+		},
+		{
+			"unknown field",
+			runtime.Values{
+				"unknown": "value",
+			},
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &annotations.HttpRule{}
+
+			if err := tt.vv.Populate(got); (err != nil) != tt.wantErr {
+				t.Fatalf("Values.Populate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.want != nil && !proto.Equal(got, tt.want) {
+				t.Errorf("Values.Populate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+//go:build tools
+
+// Package tools tracks versions of protoc plugins that generator tests run
+// to generate message and gRPC code of test fixtures.
+package tools
+
+import (
+	_ "google.golang.org/grpc/cmd/protoc-gen-go-grpc"
+	_ "google.golang.org/protobuf/cmd/protoc-gen-go"
+)